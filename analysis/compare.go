@@ -0,0 +1,393 @@
+package analysis
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/cep21/benchparse"
+)
+
+// DefaultAlpha is the significance level below which a p-value is a significant change
+const DefaultAlpha = 0.05
+
+// Test is a statistical test of whether old and new samples of a benchmark differ
+type Test int
+
+const (
+	// MannWhitneyU is the Mann-Whitney U test.  It is a rank based test that does not assume the samples are
+	// normally distributed.  This is the test benchstat uses.
+	MannWhitneyU Test = iota
+	// WelchTTest is Welch's t-test, which assumes the samples are normally distributed but not that they have the
+	// same variance
+	WelchTTest
+)
+
+func (t Test) String() string {
+	switch t {
+	case MannWhitneyU:
+		return "Mann-Whitney U"
+	case WelchTTest:
+		return "Welch's t-test"
+	default:
+		return "Test(" + strconv.Itoa(int(t)) + ")"
+	}
+}
+
+// Verdict is the conclusion of comparing the old and new samples of a benchmark
+type Verdict int
+
+const (
+	// VerdictNotSignificant means the p-value was not below the significance level
+	VerdictNotSignificant Verdict = iota
+	// VerdictSignificant means the p-value was below the significance level
+	VerdictSignificant
+	// VerdictInsufficientSamples means there were too few samples for the test to ever find a significant change.
+	// For example, comparing results of "go test -count=1".
+	VerdictInsufficientSamples
+)
+
+func (v Verdict) String() string {
+	switch v {
+	case VerdictNotSignificant:
+		return "not significant"
+	case VerdictSignificant:
+		return "significant"
+	case VerdictInsufficientSamples:
+		return "insufficient samples"
+	default:
+		return "Verdict(" + strconv.Itoa(int(v)) + ")"
+	}
+}
+
+// Comparer configures how the benchmarks of two runs are compared
+type Comparer struct {
+	// Test decides if a change is significant.  Defaults to MannWhitneyU.
+	Test Test
+	// Alpha is the significance level.  A change is significant if the p-value of Test is below Alpha.  If zero,
+	// DefaultAlpha is used.
+	Alpha float64
+}
+
+// Comparison is the result of comparing the results of an old run to a new run
+type Comparison struct {
+	// Rows are the comparison of each unit of each benchmark in both runs.  They are in the order of the groups of
+	// the old run, then units.
+	Rows []ComparisonRow
+	// Geomeans summarize all Rows of each unit, in the order units first appear in Rows
+	Geomeans []GeomeanRow
+	// OldOnly are the groups of the old run that match no group of the new run, like a removed benchmark
+	OldOnly []*Group
+	// NewOnly are the groups of the new run that match no group of the old run, like an added benchmark
+	NewOnly []*Group
+}
+
+// ComparisonRow is the comparison of a single unit of a single benchmark between two runs
+type ComparisonRow struct {
+	// Name of the benchmark, as returned by Group.Name
+	Name string
+	// Keys are the key/value pairs the benchmark was matched on between the runs
+	Keys *benchparse.OrderedStringStringMap
+	// Unit that is compared
+	Unit string
+	// Old is the summary of the benchmark in the old run
+	Old Summary
+	// New is the summary of the benchmark in the new run
+	New Summary
+	// Delta is the percent change from the median of Old to the median of New.  For example, -10 is a 10% decrease.
+	Delta float64
+	// Test is the statistical test that computed PValue
+	Test Test
+	// PValue of Test that the old and new samples come from the same distribution.  It is NaN if Verdict is
+	// VerdictInsufficientSamples.
+	PValue float64
+	// Alpha is the significance level PValue was compared to
+	Alpha float64
+	// Verdict is the conclusion of Test
+	Verdict Verdict
+	// Significant is true if Verdict is VerdictSignificant
+	Significant bool
+}
+
+// DeltaString formats Delta as a signed percent, like "+3.21%".  Like benchstat, changes that are not significant,
+// including changes with too few samples to tell, are "~".
+func (c ComparisonRow) DeltaString() string {
+	if !c.Significant {
+		return "~"
+	}
+	return formatDelta(c.Delta)
+}
+
+// GeomeanRow is the geometric mean of the medians of every benchmark of a single unit
+type GeomeanRow struct {
+	// Unit of the benchmarks
+	Unit string
+	// Old is the geometric mean of the old medians
+	Old float64
+	// New is the geometric mean of the new medians
+	New float64
+	// Delta is the percent change from Old to New
+	Delta float64
+}
+
+// DeltaString formats Delta as a signed percent, like "+3.21%"
+func (g GeomeanRow) DeltaString() string {
+	return formatDelta(g.Delta)
+}
+
+// formatDelta formats a percent change with an explicit sign
+func formatDelta(delta float64) string {
+	if math.IsNaN(delta) || math.IsInf(delta, 0) {
+		return "?"
+	}
+	ret := strconv.FormatFloat(delta, 'f', 2, 64) + "%"
+	if delta >= 0 {
+		return "+" + ret
+	}
+	return ret
+}
+
+// Compare matches the benchmarks of an old and new run and compares every unit both reported with the default
+// Comparer.  See Comparer.Compare.
+func Compare(old *benchparse.Run, new *benchparse.Run) *Comparison {
+	return Comparer{}.Compare(old, new)
+}
+
+// Compare matches the benchmarks of an old and new run and compares every unit both reported.  Benchmarks are matched
+// by their AllKeyValuePairs, except for keys that describe the runs, like a commit or machine, rather than the
+// benchmark.  Those are keys that have the same value for every result of a run, in both runs or in one run while
+// the other does not have them at all.  If ignoring those keys would match more than one benchmark of a run to the
+// same key/value pairs, those benchmarks are matched on all of their keys instead.  Benchmarks only in one of the runs
+// are not compared, and are in OldOnly or NewOnly instead.
+func (c Comparer) Compare(old *benchparse.Run, new *benchparse.Run) *Comparison {
+	allOldGroups := GroupResults(old.Results)
+	allNewGroups := GroupResults(new.Results)
+	m := newGroupMatcher(allOldGroups, allNewGroups, ignoredRunKeys(old.Results, new.Results))
+	matched := make(map[*Group]struct{})
+
+	ret := &Comparison{}
+	for _, oldGroup := range allOldGroups {
+		newGroup, ignoredKeys, exists := m.match(oldGroup)
+		if !exists {
+			ret.OldOnly = append(ret.OldOnly, oldGroup)
+			continue
+		}
+		matched[newGroup] = struct{}{}
+		for _, unit := range oldGroup.Units() {
+			newValues := newGroup.Values(unit)
+			if len(newValues) == 0 {
+				continue
+			}
+			ret.Rows = append(ret.Rows, c.compareSamples(oldGroup, newGroup, unit, oldGroup.Values(unit), newValues, ignoredKeys))
+		}
+	}
+	for _, g := range allNewGroups {
+		if _, exists := matched[g]; !exists {
+			ret.NewOnly = append(ret.NewOnly, g)
+		}
+	}
+	ret.Geomeans = geomeanRows(ret.Rows)
+	return ret
+}
+
+// compareSamples creates the ComparisonRow of a single unit
+func (c Comparer) compareSamples(oldGroup *Group, newGroup *Group, unit string, oldValues []float64, newValues []float64, ignoredKeys map[string]struct{}) ComparisonRow {
+	s := Summarizer{}
+	ret := ComparisonRow{
+		Name:  oldGroup.Name(),
+		Keys:  withoutKeys(oldGroup.Keys, ignoredKeys),
+		Unit:  unit,
+		Old:   s.summarize(oldGroup, unit, oldValues),
+		New:   s.summarize(newGroup, unit, newValues),
+		Test:  c.Test,
+		Alpha: c.alpha(),
+	}
+	ret.Delta = percentChange(ret.Old.Median, ret.New.Median)
+	ret.PValue, ret.Verdict = c.test(oldValues, newValues)
+	ret.Significant = ret.Verdict == VerdictSignificant
+	return ret
+}
+
+// alpha returns the significance level of the comparison
+func (c Comparer) alpha() float64 {
+	if c.Alpha == 0 {
+		return DefaultAlpha
+	}
+	return c.Alpha
+}
+
+// test runs the configured Test on the samples
+func (c Comparer) test(oldValues []float64, newValues []float64) (float64, Verdict) {
+	var pValue float64
+	switch c.Test {
+	case WelchTTest:
+		if len(oldValues) < 2 || len(newValues) < 2 {
+			return math.NaN(), VerdictInsufficientSamples
+		}
+		pValue = welchTTest(oldValues, newValues)
+	default:
+		if mannWhitneyMinP(len(oldValues), len(newValues)) >= c.alpha() {
+			return math.NaN(), VerdictInsufficientSamples
+		}
+		pValue = mannWhitneyUTest(oldValues, newValues)
+	}
+	if pValue < c.alpha() {
+		return pValue, VerdictSignificant
+	}
+	return pValue, VerdictNotSignificant
+}
+
+// geomeanRows creates one GeomeanRow for each unit of rows
+func geomeanRows(rows []ComparisonRow) []GeomeanRow {
+	var units []string
+	oldMedians := make(map[string][]float64)
+	newMedians := make(map[string][]float64)
+	for _, r := range rows {
+		if _, exists := oldMedians[r.Unit]; !exists {
+			units = append(units, r.Unit)
+		}
+		oldMedians[r.Unit] = append(oldMedians[r.Unit], r.Old.Median)
+		newMedians[r.Unit] = append(newMedians[r.Unit], r.New.Median)
+	}
+	ret := make([]GeomeanRow, 0, len(units))
+	for _, unit := range units {
+		g := GeomeanRow{
+			Unit: unit,
+			Old:  geomean(oldMedians[unit]),
+			New:  geomean(newMedians[unit]),
+		}
+		g.Delta = percentChange(g.Old, g.New)
+		ret = append(ret, g)
+	}
+	return ret
+}
+
+// percentChange returns the percent change from old to new
+func percentChange(old float64, new float64) float64 {
+	return (new - old) / old * 100
+}
+
+// runKeys returns every configuration key that has the same value for every result.  Keys that are also part of the
+// name of any result are never returned, since they identify the benchmark.
+func runKeys(results []benchparse.BenchmarkResult) map[string]struct{} {
+	ret := make(map[string]struct{})
+	if len(results) == 0 || results[0].Configuration == nil {
+		return ret
+	}
+	first := results[0].Configuration
+	for _, k := range first.Order {
+		ret[k] = struct{}{}
+	}
+	for _, r := range results {
+		for k := range ret {
+			if r.Configuration == nil {
+				delete(ret, k)
+				continue
+			}
+			if v, exists := r.Configuration.Contents[k]; !exists || v != first.Contents[k] {
+				delete(ret, k)
+			}
+		}
+		for _, k := range r.NameAsKeyValue().Order {
+			delete(ret, k)
+		}
+	}
+	return ret
+}
+
+// allKeys returns every configuration key and key=value sub benchmark key of any result
+func allKeys(results []benchparse.BenchmarkResult) map[string]struct{} {
+	ret := make(map[string]struct{})
+	for _, r := range results {
+		for _, k := range r.AllKeyValuePairs().Order {
+			ret[k] = struct{}{}
+		}
+	}
+	return ret
+}
+
+// ignoredRunKeys returns the keys Compare does not match benchmarks on.  A key is ignored if it is a runKeys of at
+// least one run, and for each run is either one of its runKeys or not a key of any of its results.
+func ignoredRunKeys(old []benchparse.BenchmarkResult, new []benchparse.BenchmarkResult) map[string]struct{} {
+	oldRunKeys, newRunKeys := runKeys(old), runKeys(new)
+	oldKeys, newKeys := allKeys(old), allKeys(new)
+	ret := make(map[string]struct{})
+	for _, candidates := range []map[string]struct{}{oldRunKeys, newRunKeys} {
+		for k := range candidates {
+			_, oldRunKey := oldRunKeys[k]
+			_, inOld := oldKeys[k]
+			_, newRunKey := newRunKeys[k]
+			_, inNew := newKeys[k]
+			if (oldRunKey || !inOld) && (newRunKey || !inNew) {
+				ret[k] = struct{}{}
+			}
+		}
+	}
+	return ret
+}
+
+// groupMatcher finds the group of a new run that matches a group of an old run
+type groupMatcher struct {
+	ignoredKeys map[string]struct{}
+	// newGroups are the new groups by their identity without ignoredKeys
+	newGroups map[string]*Group
+	// newGroupsByAllKeys are the new groups by the identity of all of their keys
+	newGroupsByAllKeys map[string]*Group
+	// ambiguous are the identities without ignoredKeys that more than one group of the same run has
+	ambiguous map[string]struct{}
+}
+
+func newGroupMatcher(oldGroups []*Group, newGroups []*Group, ignoredKeys map[string]struct{}) *groupMatcher {
+	ret := &groupMatcher{
+		ignoredKeys:        ignoredKeys,
+		newGroups:          make(map[string]*Group, len(newGroups)),
+		newGroupsByAllKeys: make(map[string]*Group, len(newGroups)),
+		ambiguous:          make(map[string]struct{}),
+	}
+	for _, groups := range [][]*Group{oldGroups, newGroups} {
+		seen := make(map[string]struct{}, len(groups))
+		for _, g := range groups {
+			id := identityWithout(g.Keys, ignoredKeys)
+			if _, exists := seen[id]; exists {
+				ret.ambiguous[id] = struct{}{}
+			}
+			seen[id] = struct{}{}
+		}
+	}
+	for _, g := range newGroups {
+		ret.newGroups[identityWithout(g.Keys, ignoredKeys)] = g
+		ret.newGroupsByAllKeys[identity(g.Keys)] = g
+	}
+	return ret
+}
+
+// match returns the new group that matches oldGroup, and the keys that were ignored to match them.  Returns false if
+// no new group matches.
+func (m *groupMatcher) match(oldGroup *Group) (*Group, map[string]struct{}, bool) {
+	id := identityWithout(oldGroup.Keys, m.ignoredKeys)
+	if _, exists := m.ambiguous[id]; exists {
+		g, exists := m.newGroupsByAllKeys[identity(oldGroup.Keys)]
+		return g, nil, exists
+	}
+	g, exists := m.newGroups[id]
+	return g, m.ignoredKeys, exists
+}
+
+// withoutKeys returns a copy of keys without any of the keys inside ignored
+func withoutKeys(keys *benchparse.OrderedStringStringMap, ignored map[string]struct{}) *benchparse.OrderedStringStringMap {
+	ret := &benchparse.OrderedStringStringMap{
+		Contents: make(map[string]string, len(keys.Order)),
+	}
+	for _, k := range keys.Order {
+		if _, exists := ignored[k]; exists {
+			continue
+		}
+		ret.Contents[k] = keys.Contents[k]
+		ret.Order = append(ret.Order, k)
+	}
+	return ret
+}
+
+// identityWithout returns the identity of keys when ignoring any key inside ignored
+func identityWithout(keys *benchparse.OrderedStringStringMap, ignored map[string]struct{}) string {
+	return identity(withoutKeys(keys, ignored))
+}
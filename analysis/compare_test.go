@@ -0,0 +1,204 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cep21/benchparse"
+	"github.com/cep21/benchparse/internal/benchtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	old := benchtest.DecodeRun(t, `commit: 7cd9055
+BenchmarkDecode/size=1e4-8 100 100 ns/op 10 B/op
+BenchmarkDecode/size=1e4-8 100 101 ns/op 10 B/op
+BenchmarkDecode/size=1e4-8 100 102 ns/op 10 B/op
+BenchmarkDecode/size=1e4-8 100 103 ns/op 10 B/op
+BenchmarkDecode/size=1e4-8 100 104 ns/op 10 B/op
+BenchmarkDecode/size=1e5-8 100 1000 ns/op
+BenchmarkDecode/size=1e5-8 100 1010 ns/op
+BenchmarkDecode/size=1e5-8 100 1020 ns/op
+BenchmarkDecode/size=1e5-8 100 1030 ns/op
+BenchmarkDecode/size=1e5-8 100 1040 ns/op
+BenchmarkOnlyOld-8 100 1040 ns/op
+`)
+	new := benchtest.DecodeRun(t, `commit: ab322f4
+BenchmarkDecode/size=1e4-8 100 80 ns/op 10 B/op
+BenchmarkDecode/size=1e4-8 100 81 ns/op 10 B/op
+BenchmarkDecode/size=1e4-8 100 82 ns/op 10 B/op
+BenchmarkDecode/size=1e4-8 100 83 ns/op 10 B/op
+BenchmarkDecode/size=1e4-8 100 84 ns/op 10 B/op
+BenchmarkDecode/size=1e5-8 100 1041 ns/op
+BenchmarkDecode/size=1e5-8 100 1001 ns/op
+BenchmarkDecode/size=1e5-8 100 1011 ns/op
+BenchmarkDecode/size=1e5-8 100 1021 ns/op
+BenchmarkDecode/size=1e5-8 100 1031 ns/op
+BenchmarkOnlyNew-8 100 1040 ns/op
+`)
+	c := Compare(old, new)
+	require.Len(t, c.Rows, 3)
+
+	r := c.Rows[0]
+	require.Equal(t, "Decode/size=1e4", r.Name)
+	require.Equal(t, []string{"BenchmarkDecode", "size"}, r.Keys.Order)
+	require.Equal(t, "ns/op", r.Unit)
+	require.Equal(t, 102.0, r.Old.Median)
+	require.Equal(t, 82.0, r.New.Median)
+	require.InDelta(t, -19.6078, r.Delta, 1e-4)
+	require.InDelta(t, 2.0/252, r.PValue, 1e-12)
+	require.True(t, r.Significant)
+	require.Equal(t, "-19.61%", r.DeltaString())
+
+	r = c.Rows[1]
+	require.Equal(t, "B/op", r.Unit)
+	require.Equal(t, 0.0, r.Delta)
+	require.False(t, r.Significant)
+	require.Equal(t, "~", r.DeltaString())
+
+	r = c.Rows[2]
+	require.Equal(t, "Decode/size=1e5", r.Name)
+	require.Equal(t, "ns/op", r.Unit)
+	require.False(t, r.Significant)
+	require.Equal(t, "~", r.DeltaString())
+
+	require.Len(t, c.Geomeans, 2)
+	require.Equal(t, "ns/op", c.Geomeans[0].Unit)
+	require.InDelta(t, 322.552, c.Geomeans[0].Old, 1e-3)
+	require.InDelta(t, 289.348, c.Geomeans[0].New, 1e-3)
+	require.Equal(t, "-10.29%", c.Geomeans[0].DeltaString())
+	require.Equal(t, "B/op", c.Geomeans[1].Unit)
+	require.Equal(t, "+0.00%", c.Geomeans[1].DeltaString())
+
+	require.Len(t, c.OldOnly, 1)
+	require.Equal(t, "OnlyOld", c.OldOnly[0].Name())
+	require.Len(t, c.NewOnly, 1)
+	require.Equal(t, "OnlyNew", c.NewOnly[0].Name())
+}
+
+func TestCompare_differentNames(t *testing.T) {
+	old := benchtest.DecodeRun(t, "BenchmarkA 100 100 ns/op\n")
+	new := benchtest.DecodeRun(t, "BenchmarkB 100 100 ns/op\n")
+	c := Compare(old, new)
+	require.Empty(t, c.Rows)
+	require.Len(t, c.OldOnly, 1)
+	require.Equal(t, "A", c.OldOnly[0].Name())
+	require.Len(t, c.NewOnly, 1)
+	require.Equal(t, "B", c.NewOnly[0].Name())
+}
+
+func TestCompare_keyInOneRun(t *testing.T) {
+	old := benchtest.DecodeRun(t, "cpu: x\nBenchmarkA 1 10 ns/op\nBenchmarkA 1 10 ns/op\n")
+	new := benchtest.DecodeRun(t, "BenchmarkA 1 20 ns/op\nBenchmarkA 1 20 ns/op\n")
+	c := Compare(old, new)
+	require.Len(t, c.Rows, 1)
+	require.Equal(t, "A", c.Rows[0].Name)
+	require.Equal(t, []string{"BenchmarkA"}, c.Rows[0].Keys.Order)
+	require.Equal(t, 100.0, c.Rows[0].Delta)
+	require.Empty(t, c.OldOnly)
+	require.Empty(t, c.NewOnly)
+
+	c = Compare(new, old)
+	require.Len(t, c.Rows, 1)
+	require.Equal(t, -50.0, c.Rows[0].Delta)
+}
+
+func TestCompare_keyVaryingInOneRun(t *testing.T) {
+	// cpu tells apart the benchmarks of the old run, so it is matched on even though the new run does not have it
+	old := benchtest.DecodeRun(t, "cpu: a\nBenchmarkA 1 10 ns/op\ncpu: b\nBenchmarkA 1 10 ns/op\n")
+	new := benchtest.DecodeRun(t, "BenchmarkA 1 20 ns/op\n")
+	c := Compare(old, new)
+	require.Empty(t, c.Rows)
+	require.Len(t, c.OldOnly, 2)
+	require.Len(t, c.NewOnly, 1)
+}
+
+func TestCompare_varyingConfiguration(t *testing.T) {
+	old := benchtest.DecodeRun(t, "cpu: a\nBenchmarkA 100 100 ns/op\ncpu: b\nBenchmarkA 100 100 ns/op\n")
+	new := benchtest.DecodeRun(t, "cpu: b\nBenchmarkA 100 100 ns/op\n")
+	c := Compare(old, new)
+	require.Len(t, c.Rows, 1)
+	require.Equal(t, "b", c.Rows[0].Keys.Contents["cpu"])
+}
+
+func TestGroupMatcher_match(t *testing.T) {
+	group := func(keys ...string) *Group {
+		ret := &Group{Keys: &benchparse.OrderedStringStringMap{Contents: make(map[string]string)}}
+		for i := 0; i < len(keys); i += 2 {
+			ret.Keys.Contents[keys[i]] = keys[i+1]
+			ret.Keys.Order = append(ret.Keys.Order, keys[i])
+		}
+		return ret
+	}
+	ignored := map[string]struct{}{"cpu": {}}
+	oldA, oldB := group("BenchmarkA", "", "cpu", "a"), group("BenchmarkB", "", "cpu", "a")
+	newA1, newA2, newB := group("BenchmarkA", "", "cpu", "a"), group("BenchmarkA", "", "cpu", "b"), group("BenchmarkB", "", "cpu", "b")
+	m := newGroupMatcher([]*Group{oldA, oldB}, []*Group{newA1, newA2, newB}, ignored)
+
+	// Both new A groups are A without cpu, so A is matched on cpu as well
+	g, ignoredKeys, exists := m.match(oldA)
+	require.True(t, exists)
+	require.True(t, g == newA1)
+	require.Empty(t, ignoredKeys)
+
+	g, ignoredKeys, exists = m.match(oldB)
+	require.True(t, exists)
+	require.True(t, g == newB)
+	require.Equal(t, ignored, ignoredKeys)
+
+	_, _, exists = newGroupMatcher(nil, []*Group{newA2, newA1}, ignored).match(group("BenchmarkA", "", "cpu", "c"))
+	require.False(t, exists)
+}
+
+func TestComparer_Compare(t *testing.T) {
+	old := benchtest.DecodeRun(t, `BenchmarkA 100 100 ns/op
+BenchmarkA 100 101 ns/op
+BenchmarkA 100 102 ns/op
+BenchmarkA 100 103 ns/op
+BenchmarkA 100 104 ns/op
+BenchmarkOnce 100 104 ns/op
+`)
+	new := benchtest.DecodeRun(t, `BenchmarkA 100 90 ns/op
+BenchmarkA 100 91 ns/op
+BenchmarkA 100 92 ns/op
+BenchmarkA 100 93 ns/op
+BenchmarkA 100 94 ns/op
+BenchmarkOnce 100 14 ns/op
+`)
+	t.Run("mannwhitney", func(t *testing.T) {
+		c := Compare(old, new)
+		require.Len(t, c.Rows, 2)
+		require.Equal(t, MannWhitneyU, c.Rows[0].Test)
+		require.Equal(t, DefaultAlpha, c.Rows[0].Alpha)
+		require.Equal(t, VerdictSignificant, c.Rows[0].Verdict)
+		require.InDelta(t, 2.0/252, c.Rows[0].PValue, 1e-12)
+
+		require.Equal(t, VerdictInsufficientSamples, c.Rows[1].Verdict)
+		require.True(t, math.IsNaN(c.Rows[1].PValue))
+		require.False(t, c.Rows[1].Significant)
+		require.Equal(t, "~", c.Rows[1].DeltaString())
+	})
+	t.Run("welch", func(t *testing.T) {
+		c := Comparer{Test: WelchTTest}.Compare(old, new)
+		require.Len(t, c.Rows, 2)
+		require.Equal(t, WelchTTest, c.Rows[0].Test)
+		require.Equal(t, VerdictSignificant, c.Rows[0].Verdict)
+		require.InDelta(t, 8.488e-6, c.Rows[0].PValue, 1e-8)
+		require.Equal(t, VerdictInsufficientSamples, c.Rows[1].Verdict)
+	})
+	t.Run("alpha", func(t *testing.T) {
+		c := Comparer{Alpha: 0.001}.Compare(old, new)
+		require.Equal(t, 0.001, c.Rows[0].Alpha)
+		// Five samples can never be significant at 0.001 with Mann-Whitney U
+		require.Equal(t, VerdictInsufficientSamples, c.Rows[0].Verdict)
+
+		c = Comparer{Test: WelchTTest, Alpha: 0.001}.Compare(old, new)
+		require.Equal(t, VerdictSignificant, c.Rows[0].Verdict)
+	})
+}
+
+func TestVerdict_String(t *testing.T) {
+	require.Equal(t, "insufficient samples", VerdictInsufficientSamples.String())
+	require.Equal(t, "Verdict(10)", Verdict(10).String())
+	require.Equal(t, "Welch's t-test", WelchTTest.String())
+}
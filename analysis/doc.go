@@ -0,0 +1,12 @@
+/*
+Package analysis computes statistics over decoded benchmark runs.  Results are grouped by benchmark identity, which is
+every key/value pair of BenchmarkResult.AllKeyValuePairs, so repeated runs of a benchmark (for example from
+"go test -count=10") end up in the same Group.  Note that AllKeyValuePairs removes the "-N" GOMAXPROCS suffix of a
+benchmark name.  Decode with benchparse.Decoder.ProcsKey set if benchmarks run with different -cpu values should not
+be grouped together.
+
+Compare matches the groups of two runs and tests if each change is significant, like benchstat does.  OutlierFilter
+removes noisy results from a run before summarizing or comparing it.  Pivot projects a run into a Table, with the values
+of some keys as rows, others as columns, and every other key aggregated away.
+*/
+package analysis
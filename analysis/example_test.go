@@ -0,0 +1,55 @@
+package analysis_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cep21/benchparse"
+	"github.com/cep21/benchparse/analysis"
+)
+
+func ExampleSummarize() {
+	d := benchparse.Decoder{}
+	run, err := d.Decode(strings.NewReader(`BenchmarkDecode-8   	     100	    154125 ns/op
+BenchmarkDecode-8   	     100	    155125 ns/op
+BenchmarkDecode-8   	     100	    153125 ns/op
+`))
+	if err != nil {
+		panic(err)
+	}
+	for _, s := range analysis.Summarize(run) {
+		fmt.Printf("%s %s n=%d median=%.0f\n", s.Group.Name(), s.Unit, s.N, s.Median)
+	}
+	// Output: Decode ns/op n=3 median=154125
+}
+
+func ExampleCompare() {
+	d := benchparse.Decoder{}
+	old, err := d.Decode(strings.NewReader(`BenchmarkDecode-8 100 154125 ns/op
+BenchmarkDecode-8 100 155125 ns/op
+BenchmarkDecode-8 100 153125 ns/op
+BenchmarkDecode-8 100 154525 ns/op
+BenchmarkDecode-8 100 154225 ns/op
+`))
+	if err != nil {
+		panic(err)
+	}
+	new, err := d.Decode(strings.NewReader(`BenchmarkDecode-8 100 144125 ns/op
+BenchmarkDecode-8 100 145125 ns/op
+BenchmarkDecode-8 100 143125 ns/op
+BenchmarkDecode-8 100 144525 ns/op
+BenchmarkDecode-8 100 144225 ns/op
+`))
+	if err != nil {
+		panic(err)
+	}
+	c := analysis.Compare(old, new)
+	for _, r := range c.Rows {
+		fmt.Printf("%s %s %s (p=%.3f n=%d+%d)\n", r.Name, r.Unit, r.DeltaString(), r.PValue, r.Old.N, r.New.N)
+	}
+	for _, g := range c.Geomeans {
+		fmt.Printf("geomean %s %s\n", g.Unit, g.DeltaString())
+	}
+	// Output: Decode ns/op -6.48% (p=0.008 n=5+5)
+	// geomean ns/op -6.48%
+}
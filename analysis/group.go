@@ -0,0 +1,88 @@
+package analysis
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cep21/benchparse"
+)
+
+// Group is every result of a Run that shares the same benchmark identity
+type Group struct {
+	// Keys are the AllKeyValuePairs of the first result of the group.  Every result of the group has the same pairs,
+	// but maybe in a different order.
+	Keys *benchparse.OrderedStringStringMap
+	// Results of the group, in the order they were in the Run
+	Results []benchparse.BenchmarkResult
+	// indexes are the index of each of Results in the slice passed to GroupResults
+	indexes []int
+}
+
+// GroupResults groups results by their AllKeyValuePairs.  Groups are in the order their first result appears in
+// results.
+func GroupResults(results []benchparse.BenchmarkResult) []*Group {
+	var ret []*Group
+	groupsByIdentity := make(map[string]*Group)
+	for i, r := range results {
+		keys := r.AllKeyValuePairs()
+		id := identity(keys)
+		g, exists := groupsByIdentity[id]
+		if !exists {
+			g = &Group{
+				Keys: keys,
+			}
+			groupsByIdentity[id] = g
+			ret = append(ret, g)
+		}
+		g.Results = append(g.Results, r)
+		g.indexes = append(g.indexes, i)
+	}
+	return ret
+}
+
+// identity returns a string that is the same for any two maps with the same key/value pairs, regardless of order
+func identity(keys *benchparse.OrderedStringStringMap) string {
+	pairs := make([]string, 0, len(keys.Order))
+	for _, k := range keys.Order {
+		pairs = append(pairs, strconv.Quote(k)+"="+strconv.Quote(keys.Contents[k]))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, " ")
+}
+
+// Name returns the name of the benchmark of this group without the "Benchmark" prefix and "-N" suffix.  For example
+// "Decode/text=digits/level=speed/size=1e4".
+func (g *Group) Name() string {
+	if len(g.Results) == 0 {
+		return ""
+	}
+	name := g.Results[0].ParsedName()
+	return strings.Join(append([]string{name.Function}, name.SubBenchmarks...), "/")
+}
+
+// Units returns every unit reported by a result of this group, in the order they are first reported
+func (g *Group) Units() []string {
+	var ret []string
+	seen := make(map[string]struct{})
+	for _, r := range g.Results {
+		for _, v := range r.Values {
+			if _, exists := seen[v.Unit]; !exists {
+				seen[v.Unit] = struct{}{}
+				ret = append(ret, v.Unit)
+			}
+		}
+	}
+	return ret
+}
+
+// Values returns the value of unit for every result of this group that reported unit
+func (g *Group) Values(unit string) []float64 {
+	ret := make([]float64, 0, len(g.Results))
+	for _, r := range g.Results {
+		if v, exists := r.ValueByUnit(unit); exists {
+			ret = append(ret, v)
+		}
+	}
+	return ret
+}
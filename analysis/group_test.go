@@ -0,0 +1,53 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cep21/benchparse"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupResults(t *testing.T) {
+	d := benchparse.Decoder{}
+	run, err := d.Decode(strings.NewReader(`commit: 7cd9055
+BenchmarkDecode/text=digits/size=1e4-8 100 154125 ns/op
+BenchmarkDecode/text=digits/size=1e5-8 100 254125 ns/op 12 B/op
+BenchmarkDecode/text=digits/size=1e4-8 100 154126 ns/op 10 B/op
+commit: ab322f4
+BenchmarkDecode/text=digits/size=1e4-8 100 154125 ns/op
+`))
+	require.NoError(t, err)
+	groups := GroupResults(run.Results)
+	require.Len(t, groups, 3)
+	require.Len(t, groups[0].Results, 2)
+	require.Equal(t, "Decode/text=digits/size=1e4", groups[0].Name())
+	require.Equal(t, "7cd9055", groups[0].Keys.Contents["commit"])
+	require.Equal(t, []string{"ns/op", "B/op"}, groups[0].Units())
+	require.Equal(t, []float64{154125, 154126}, groups[0].Values("ns/op"))
+	require.Equal(t, []float64{10}, groups[0].Values("B/op"))
+	require.Len(t, groups[1].Results, 1)
+	require.Equal(t, "Decode/text=digits/size=1e5", groups[1].Name())
+	require.Len(t, groups[2].Results, 1)
+	require.Equal(t, "ab322f4", groups[2].Keys.Contents["commit"])
+}
+
+func TestGroupResults_order(t *testing.T) {
+	results := []benchparse.BenchmarkResult{
+		{
+			Name: "BenchmarkBob",
+			Configuration: &benchparse.OrderedStringStringMap{
+				Contents: map[string]string{"a": "1", "b": "2"},
+				Order:    []string{"a", "b"},
+			},
+		},
+		{
+			Name: "BenchmarkBob",
+			Configuration: &benchparse.OrderedStringStringMap{
+				Contents: map[string]string{"a": "1", "b": "2"},
+				Order:    []string{"b", "a"},
+			},
+		},
+	}
+	require.Len(t, GroupResults(results), 1)
+}
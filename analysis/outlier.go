@@ -0,0 +1,176 @@
+package analysis
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/cep21/benchparse"
+)
+
+// OutlierMethod is a way to decide which samples of a group are outliers
+type OutlierMethod int
+
+const (
+	// IQR rejects samples outside of Tukey's fences, [Q1 - K*IQR, Q3 + K*IQR], where IQR is the interquartile range
+	// Q3 - Q1.  K defaults to 1.5.
+	IQR OutlierMethod = iota
+	// MAD rejects samples more than K scaled median absolute deviations from the median.  The median absolute
+	// deviation is scaled by 1.4826 so it estimates the standard deviation of normally distributed samples.  K
+	// defaults to 3.5.
+	MAD
+)
+
+func (o OutlierMethod) String() string {
+	switch o {
+	case IQR:
+		return "IQR"
+	case MAD:
+		return "MAD"
+	default:
+		return "OutlierMethod(" + strconv.Itoa(int(o)) + ")"
+	}
+}
+
+// minOutlierSamples is the fewest samples a group needs before any of them are rejected as outliers
+const minOutlierSamples = 3
+
+// OutlierFilter removes results whose value of a unit is an outlier compared to the other results of its Group
+type OutlierFilter struct {
+	// Method decides which samples are outliers.  Defaults to IQR.
+	Method OutlierMethod
+	// K is how far outside of typical a sample must be to be rejected.  See IQR and MAD.  If zero, the default of
+	// Method is used.
+	K float64
+	// Unit whose value decides if a result is an outlier.  Results that did not report Unit are always kept.
+	// Defaults to benchparse.UnitRuntime.
+	Unit string
+}
+
+// OutlierGroup is the outcome of filtering the results of a single Group
+type OutlierGroup struct {
+	// Group of the input run that was filtered
+	Group *Group
+	// Unit of the samples
+	Unit string
+	// Low and High are the range of samples that are kept.  Groups with fewer than three samples are never filtered
+	// and have a range of [-Inf, +Inf].
+	Low  float64
+	High float64
+	// Kept are the samples inside [Low, High]
+	Kept []float64
+	// Rejected are the samples outside of [Low, High]
+	Rejected []float64
+}
+
+// OutlierReport is the outcome of filtering a Run
+type OutlierReport struct {
+	// Run is a copy of the filtered run without any rejected results.  It does not share Configuration or Units
+	// pointers with the filtered run, so it is safe to modify.
+	Run *benchparse.Run
+	// Rejected are copies of every rejected result, in the order of the filtered run
+	Rejected []benchparse.BenchmarkResult
+	// Groups are the outcome of filtering each group, in the order of GroupResults
+	Groups []OutlierGroup
+}
+
+// Filter groups every result of run and removes the results that are outliers compared to the rest of their group.
+// run is not modified.
+func (o OutlierFilter) Filter(run *benchparse.Run) *OutlierReport {
+	unit := o.Unit
+	if unit == "" {
+		unit = benchparse.UnitRuntime
+	}
+	ret := &OutlierReport{}
+	rejected := make(map[int]struct{})
+	for _, g := range GroupResults(run.Results) {
+		values := g.Values(unit)
+		low, high := o.fences(values)
+		outcome := OutlierGroup{
+			Group: g,
+			Unit:  unit,
+			Low:   low,
+			High:  high,
+		}
+		for i, r := range g.Results {
+			v, exists := r.ValueByUnit(unit)
+			if !exists {
+				continue
+			}
+			if v < low || v > high {
+				outcome.Rejected = append(outcome.Rejected, v)
+				rejected[g.indexes[i]] = struct{}{}
+				continue
+			}
+			outcome.Kept = append(outcome.Kept, v)
+		}
+		ret.Groups = append(ret.Groups, outcome)
+	}
+	ret.Run, ret.Rejected = copyRun(run, rejected)
+	return ret
+}
+
+// fences returns the range of values that are not outliers
+func (o OutlierFilter) fences(values []float64) (float64, float64) {
+	if len(values) < minOutlierSamples {
+		return math.Inf(-1), math.Inf(1)
+	}
+	sorted := sortedCopy(values)
+	k := o.K
+	switch o.Method {
+	case MAD:
+		if k == 0 {
+			k = 3.5
+		}
+		m := quantile(sorted, 0.5)
+		deviations := make([]float64, 0, len(sorted))
+		for _, v := range sorted {
+			deviations = append(deviations, math.Abs(v-m))
+		}
+		scaledMAD := 1.4826 * median(deviations)
+		return m - k*scaledMAD, m + k*scaledMAD
+	default:
+		if k == 0 {
+			k = 1.5
+		}
+		q1 := quantile(sorted, 0.25)
+		q3 := quantile(sorted, 0.75)
+		return q1 - k*(q3-q1), q3 + k*(q3-q1)
+	}
+}
+
+// copyRun returns a deep copy of run, from Run.Clone, without the results at the indexes inside removed, and copies
+// of the removed results.  Package sections that are out of range of the results are clamped to them.
+func copyRun(run *benchparse.Run, removed map[int]struct{}) (*benchparse.Run, []benchparse.BenchmarkResult) {
+	cloned := run.Clone()
+	ret := &benchparse.Run{
+		Results: make([]benchparse.BenchmarkResult, 0, len(run.Results)-len(removed)),
+		Units:   cloned.Units,
+	}
+	var rejected []benchparse.BenchmarkResult
+	// newIndex[i] is the index inside ret.Results of the first kept result at or after run.Results[i]
+	newIndex := make([]int, len(run.Results)+1)
+	for i, r := range cloned.Results {
+		newIndex[i] = len(ret.Results)
+		if _, exists := removed[i]; exists {
+			rejected = append(rejected, r)
+			continue
+		}
+		ret.Results = append(ret.Results, r)
+	}
+	newIndex[len(run.Results)] = len(ret.Results)
+	keptBefore := func(idx int) int {
+		if idx < 0 {
+			return 0
+		}
+		if idx >= len(newIndex) {
+			return len(ret.Results)
+		}
+		return newIndex[idx]
+	}
+	for _, p := range cloned.Packages {
+		p.ResultsStart = keptBefore(p.ResultsStart)
+		p.ResultsEnd = keptBefore(p.ResultsEnd)
+		ret.Packages = append(ret.Packages, p)
+	}
+	return ret, rejected
+}
@@ -0,0 +1,105 @@
+package analysis
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/cep21/benchparse"
+	"github.com/cep21/benchparse/internal/benchtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutlierFilter_Filter(t *testing.T) {
+	run := benchtest.DecodeRun(t, `commit: 7cd9055
+BenchmarkA 100 100 ns/op 5 B/op
+BenchmarkA 100 101 ns/op 5 B/op
+BenchmarkB 100 10 ns/op
+BenchmarkA 100 300 ns/op 5 B/op
+BenchmarkA 100 102 ns/op 5 B/op
+BenchmarkA 100 103 ns/op 5 B/op
+BenchmarkB 100 1000 ns/op
+PASS
+ok  	github.com/cep21/a	1.302s
+BenchmarkC 100 1 B/op
+`)
+	verifyFilter := func(o OutlierFilter, low float64, high float64) func(t *testing.T) {
+		return func(t *testing.T) {
+			report := o.Filter(run)
+			require.Len(t, report.Run.Results, 7)
+			require.Len(t, report.Rejected, 1)
+			require.Equal(t, "BenchmarkA 100 300 ns/op 5 B/op", report.Rejected[0].String())
+
+			require.Len(t, report.Groups, 3)
+			g := report.Groups[0]
+			require.Equal(t, "A", g.Group.Name())
+			require.Equal(t, "ns/op", g.Unit)
+			require.InDelta(t, low, g.Low, 1e-9)
+			require.InDelta(t, high, g.High, 1e-9)
+			require.Equal(t, []float64{100, 101, 102, 103}, g.Kept)
+			require.Equal(t, []float64{300}, g.Rejected)
+
+			// Too few samples to filter
+			require.True(t, math.IsInf(report.Groups[1].Low, -1))
+			require.Equal(t, []float64{10, 1000}, report.Groups[1].Kept)
+			// No samples of the unit
+			require.Empty(t, report.Groups[2].Kept)
+
+			require.Equal(t, []benchparse.PackageSection{
+				{
+					Package:      "github.com/cep21/a",
+					Status:       benchparse.PackageStatusOK,
+					Elapsed:      run.Packages[0].Elapsed,
+					ResultsStart: 0,
+					ResultsEnd:   6,
+				},
+			}, report.Run.Packages)
+
+			// The filtered run is a deep copy
+			require.Len(t, run.Results, 8)
+			require.True(t, run.Results[0].Configuration == run.Results[1].Configuration)
+			require.True(t, report.Run.Results[0].Configuration == report.Run.Results[1].Configuration)
+			require.False(t, run.Results[0].Configuration == report.Run.Results[0].Configuration)
+			require.Equal(t, run.Results[0].Configuration, report.Run.Results[0].Configuration)
+			report.Run.Results[0].Values[0].Value = 1
+			require.Equal(t, 100.0, run.Results[0].Values[0].Value)
+		}
+	}
+	t.Run("iqr", verifyFilter(OutlierFilter{}, 98, 106))
+	t.Run("mad", verifyFilter(OutlierFilter{Method: MAD}, 102-3.5*1.4826, 102+3.5*1.4826))
+	t.Run("k", verifyFilter(OutlierFilter{K: 3}, 95, 109))
+
+	t.Run("unit", func(t *testing.T) {
+		report := OutlierFilter{Unit: "B/op"}.Filter(run)
+		require.Len(t, report.Run.Results, 8)
+		require.Empty(t, report.Rejected)
+		require.Equal(t, []float64{5, 5, 5, 5, 5}, report.Groups[0].Kept)
+	})
+}
+
+func TestOutlierFilter_Filter_procsKey(t *testing.T) {
+	in := `commit: 7cd9055
+BenchmarkA-8 100 100 ns/op
+BenchmarkA-8 100 101 ns/op
+BenchmarkA-8 100 102 ns/op
+BenchmarkA-8 100 300 ns/op
+BenchmarkA-8 100 103 ns/op
+`
+	run, err := benchparse.Decoder{ProcsKey: "procs"}.Decode(strings.NewReader(in))
+	require.NoError(t, err)
+	report := OutlierFilter{}.Filter(run)
+	require.Len(t, report.Run.Results, 4)
+	require.Equal(t, "8", report.Run.Results[0].Configuration.Contents["procs"])
+	var buf bytes.Buffer
+	require.NoError(t, (&benchparse.Encoder{}).Encode(&buf, report.Run))
+	require.Equal(t, strings.Replace(in, "BenchmarkA-8 100 300 ns/op\n", "", 1), buf.String())
+}
+
+func TestOutlierFilter_Filter_packagesOutOfRange(t *testing.T) {
+	run := benchtest.DecodeRun(t, "BenchmarkA 100 100 ns/op\nBenchmarkA 100 101 ns/op\n")
+	run.Packages = []benchparse.PackageSection{{Package: "a", ResultsStart: -1, ResultsEnd: 5}}
+	report := OutlierFilter{}.Filter(run)
+	require.Equal(t, 0, report.Run.Packages[0].ResultsStart)
+	require.Equal(t, 2, report.Run.Packages[0].ResultsEnd)
+}
@@ -0,0 +1,212 @@
+package analysis
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/cep21/benchparse"
+)
+
+// Aggregation is the statistic that reduces the values of a Cell to a single value
+type Aggregation int
+
+const (
+	// AggregationMedian is the median of the values
+	AggregationMedian Aggregation = iota
+	// AggregationMean is the arithmetic mean of the values
+	AggregationMean
+	// AggregationMin is the smallest value
+	AggregationMin
+	// AggregationMax is the largest value
+	AggregationMax
+)
+
+func (a Aggregation) String() string {
+	switch a {
+	case AggregationMedian:
+		return "median"
+	case AggregationMean:
+		return "mean"
+	case AggregationMin:
+		return "min"
+	case AggregationMax:
+		return "max"
+	default:
+		return "Aggregation(" + strconv.Itoa(int(a)) + ")"
+	}
+}
+
+// of returns the statistic of s that a is
+func (a Aggregation) of(s Summary) float64 {
+	switch a {
+	case AggregationMean:
+		return s.Mean
+	case AggregationMin:
+		return s.Min
+	case AggregationMax:
+		return s.Max
+	default:
+		return s.Median
+	}
+}
+
+// Pivot projects the results of a run into a Table.  The values of the Rows keys of a result pick its row, and the
+// values of the Columns keys pick its column.  Keys are those of BenchmarkResult.AllKeyValuePairs, so both
+// configuration keys and key=value sub benchmarks like "size" of "BenchmarkDecode/size=1e4" work.  Every other key is
+// aggregated away: all results with the same row and column values are summarized into one cell.
+//
+// For example, Rows of "text" and "level", Columns of "size", and a Unit of "ns/op" is a table with a row for each
+// text and level pair, a column for each size, and the median ns/op of each in the cells.
+type Pivot struct {
+	// Rows are the keys whose values tell apart rows.  Without any, the table has a single row.
+	Rows []string
+	// Columns are the keys whose values tell apart columns.  Without any, the table has a single column.
+	Columns []string
+	// Unit is the unit of the values in the cells.  It defaults to the first unit of the first result.  Results that
+	// did not report Unit are left out of the table.
+	Unit string
+	// Aggregation reduces the values of each cell to Cell.Value.  It defaults to AggregationMedian.
+	Aggregation Aggregation
+	// Summarizer summarizes the values of each cell
+	Summarizer Summarizer
+}
+
+// Table is the results of a run pivoted into rows and columns by Pivot
+type Table struct {
+	// Unit of the values of the cells
+	Unit string
+	// Aggregation that reduced the values of each cell to Cell.Value
+	Aggregation Aggregation
+	// RowKeys and ColumnKeys are the Rows and Columns keys of the Pivot
+	RowKeys    []string
+	ColumnKeys []string
+	// Rows are the values of RowKeys for each row, and Columns are the values of ColumnKeys for each column.  They
+	// are in the order they first appear in the run.  A key a result does not have has the value "".
+	Rows    [][]string
+	Columns [][]string
+	// Cells are the cells of each row, then column.  Cells[i][j] is the cell of Rows[i] and Columns[j].
+	Cells [][]Cell
+}
+
+// Cell is every value of the unit of a Table that has the same row and column
+type Cell struct {
+	// Summary of the values.  Its Group is every result of the cell.  Summary.N is zero if no result has the row and
+	// column of the cell.
+	Summary Summary
+	// Value is the Aggregation of the values.  It is NaN if there are none.
+	Value float64
+}
+
+// Empty returns true if no result has the row and column of the cell
+func (c Cell) Empty() bool {
+	return c.Summary.N == 0
+}
+
+// Table pivots the results of run into a Table
+func (p Pivot) Table(run *benchparse.Run) *Table {
+	unit := p.Unit
+	if unit == "" && len(run.Results) > 0 && len(run.Results[0].Values) > 0 {
+		unit = run.Results[0].Values[0].Unit
+	}
+	ret := &Table{
+		Unit:        unit,
+		Aggregation: p.Aggregation,
+		RowKeys:     p.Rows,
+		ColumnKeys:  p.Columns,
+	}
+	rows := newPivotAxis(p.Rows)
+	columns := newPivotAxis(p.Columns)
+	groups := make(map[[2]int]*Group)
+	for _, r := range run.Results {
+		if _, exists := r.ValueByUnit(unit); !exists {
+			continue
+		}
+		pairs := r.AllKeyValuePairs()
+		idx := [2]int{rows.index(pairs), columns.index(pairs)}
+		g, exists := groups[idx]
+		if !exists {
+			g = &Group{Keys: cellKeys(pairs, p.Rows, p.Columns)}
+			groups[idx] = g
+		}
+		g.Results = append(g.Results, r)
+	}
+	ret.Rows = rows.values
+	ret.Columns = columns.values
+	ret.Cells = make([][]Cell, len(ret.Rows))
+	for i := range ret.Rows {
+		ret.Cells[i] = make([]Cell, len(ret.Columns))
+		for j := range ret.Columns {
+			g, exists := groups[[2]int{i, j}]
+			if !exists {
+				g = &Group{}
+			}
+			s := p.Summarizer.SummarizeGroup(g, unit)
+			ret.Cells[i][j] = Cell{Summary: s, Value: p.Aggregation.of(s)}
+			if s.N == 0 {
+				ret.Cells[i][j].Value = math.NaN()
+			}
+		}
+	}
+	return ret
+}
+
+// pivotAxis is the rows or columns of a Table
+type pivotAxis struct {
+	keys    []string
+	values  [][]string
+	indexes map[string]int
+}
+
+func newPivotAxis(keys []string) *pivotAxis {
+	return &pivotAxis{
+		keys:    keys,
+		indexes: make(map[string]int),
+	}
+}
+
+// index returns the index of the row or column with the values of keys inside pairs, adding it if it is new
+func (a *pivotAxis) index(pairs *benchparse.OrderedStringStringMap) int {
+	values := make([]string, 0, len(a.keys))
+	var id []byte
+	for _, k := range a.keys {
+		values = append(values, pairs.Contents[k])
+		id = strconv.AppendQuote(id, pairs.Contents[k])
+	}
+	idx, exists := a.indexes[string(id)]
+	if !exists {
+		idx = len(a.values)
+		a.indexes[string(id)] = idx
+		a.values = append(a.values, values)
+	}
+	return idx
+}
+
+// cellKeys returns the pairs of the row and column keys, which are the keys every result of a cell shares
+func cellKeys(pairs *benchparse.OrderedStringStringMap, rows []string, columns []string) *benchparse.OrderedStringStringMap {
+	ret := &benchparse.OrderedStringStringMap{
+		Contents: make(map[string]string),
+	}
+	for _, keys := range [][]string{rows, columns} {
+		for _, k := range keys {
+			if _, exists := ret.Contents[k]; !exists {
+				ret.Order = append(ret.Order, k)
+			}
+			ret.Contents[k] = pairs.Contents[k]
+		}
+	}
+	return ret
+}
+
+// ColumnName returns a name for the column at index j, like "size=1e4" or "level=speed/size=1e4".  It is the unit
+// of the table if there are no column keys.
+func (t *Table) ColumnName(j int) string {
+	if len(t.ColumnKeys) == 0 {
+		return t.Unit
+	}
+	pairs := make([]string, 0, len(t.ColumnKeys))
+	for i, k := range t.ColumnKeys {
+		pairs = append(pairs, k+"="+t.Columns[j][i])
+	}
+	return strings.Join(pairs, "/")
+}
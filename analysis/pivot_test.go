@@ -0,0 +1,82 @@
+package analysis
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/cep21/benchparse"
+	"github.com/stretchr/testify/require"
+)
+
+const pivotExample = `commit: 7cd9055
+BenchmarkDecode/text=digits/level=speed/size=1e4-8 100 10 ns/op 5 B/op
+BenchmarkDecode/text=digits/level=speed/size=1e4-8 100 12 ns/op 5 B/op
+BenchmarkDecode/text=digits/level=speed/size=1e4-8 100 11 ns/op 5 B/op
+BenchmarkDecode/text=digits/level=speed/size=1e6-8 100 1000 ns/op
+BenchmarkDecode/text=twain/level=speed/size=1e4-8 100 20 ns/op
+BenchmarkDecode/text=twain/level=best/size=1e4-8 100 30 ns/op
+commit: 7cd9056
+BenchmarkDecode/text=twain/level=best/size=1e4-8 100 40 ns/op
+BenchmarkDecode/text=twain/level=best/size=1e6-8 100 50 ns/op 7 B/op
+`
+
+func TestPivot_Table(t *testing.T) {
+	d := benchparse.Decoder{}
+	run, err := d.Decode(strings.NewReader(pivotExample))
+	require.NoError(t, err)
+
+	table := Pivot{Rows: []string{"text", "level"}, Columns: []string{"size"}}.Table(run)
+	require.Equal(t, "ns/op", table.Unit)
+	require.Equal(t, AggregationMedian, table.Aggregation)
+	require.Equal(t, [][]string{{"digits", "speed"}, {"twain", "speed"}, {"twain", "best"}}, table.Rows)
+	require.Equal(t, [][]string{{"1e4"}, {"1e6"}}, table.Columns)
+	require.Equal(t, "size=1e6", table.ColumnName(1))
+	values := func(table *Table) [][]float64 {
+		ret := make([][]float64, len(table.Cells))
+		for i, row := range table.Cells {
+			for _, c := range row {
+				ret[i] = append(ret[i], c.Value)
+			}
+		}
+		return ret
+	}
+	cells := values(table)
+	require.Equal(t, []float64{11, 1000}, cells[0])
+	require.Equal(t, 20.0, cells[1][0])
+	require.True(t, math.IsNaN(cells[1][1]))
+	require.True(t, table.Cells[1][1].Empty())
+	// Both commits are aggregated into the same cell
+	require.Equal(t, []float64{35, 50}, cells[2])
+	require.Equal(t, []float64{30, 40}, table.Cells[2][0].Summary.Samples)
+	require.Len(t, table.Cells[2][0].Summary.Group.Results, 2)
+	require.Equal(t, []string{"text", "level", "size"}, table.Cells[2][0].Summary.Group.Keys.Order)
+	require.Equal(t, "best", table.Cells[2][0].Summary.Group.Keys.Contents["level"])
+
+	table = Pivot{Rows: []string{"commit"}, Unit: "B/op", Aggregation: AggregationMax}.Table(run)
+	require.Equal(t, [][]string{{"7cd9055"}, {"7cd9056"}}, table.Rows)
+	require.Equal(t, [][]string{{}}, table.Columns)
+	require.Equal(t, "B/op", table.ColumnName(0))
+	require.Equal(t, [][]float64{{5}, {7}}, values(table))
+
+	table = Pivot{Columns: []string{"missing"}, Aggregation: AggregationMin}.Table(run)
+	require.Equal(t, [][]string{{}}, table.Rows)
+	require.Equal(t, [][]string{{""}}, table.Columns)
+	require.Equal(t, [][]float64{{10}}, values(table))
+
+	table = Pivot{Unit: "allocs/op"}.Table(run)
+	require.Empty(t, table.Rows)
+	require.Empty(t, table.Cells)
+
+	table = Pivot{}.Table(&benchparse.Run{})
+	require.Equal(t, "", table.Unit)
+	require.Empty(t, table.Cells)
+}
+
+func TestAggregation_String(t *testing.T) {
+	require.Equal(t, "median", AggregationMedian.String())
+	require.Equal(t, "mean", AggregationMean.String())
+	require.Equal(t, "min", AggregationMin.String())
+	require.Equal(t, "max", AggregationMax.String())
+	require.Equal(t, "Aggregation(9)", Aggregation(9).String())
+}
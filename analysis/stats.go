@@ -0,0 +1,321 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+)
+
+// mean returns the arithmetic mean of values, or NaN if there are none
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// median returns the median of values, or NaN if there are none
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	sorted := sortedCopy(values)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid]
+	}
+	return (sorted[mid-1] + sorted[mid]) / 2
+}
+
+// variance returns the sample variance of values.  It is zero for fewer than two values.
+func variance(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	m := mean(values)
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - m) * (v - m)
+	}
+	return sum / float64(len(values)-1)
+}
+
+// sortedCopy returns a sorted copy of values, leaving values untouched
+func sortedCopy(values []float64) []float64 {
+	ret := make([]float64, len(values))
+	copy(ret, values)
+	sort.Float64s(ret)
+	return ret
+}
+
+// quantile returns the q quantile, 0 <= q <= 1, of sorted using linear interpolation between closest ranks
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	pos := q * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(pos-float64(lower))
+}
+
+// studentTCDF returns P(T <= t) for a Student's t distribution with df degrees of freedom
+func studentTCDF(t float64, df float64) float64 {
+	if math.IsInf(t, 1) {
+		return 1
+	}
+	if math.IsInf(t, -1) {
+		return 0
+	}
+	tail := 0.5 * regularizedIncompleteBeta(df/(df+t*t), df/2, 0.5)
+	if t > 0 {
+		return 1 - tail
+	}
+	return tail
+}
+
+// studentTQuantile returns the t such that P(T <= t) == p for a Student's t distribution with df degrees of freedom
+func studentTQuantile(p float64, df float64) float64 {
+	if p <= 0 {
+		return math.Inf(-1)
+	}
+	if p >= 1 {
+		return math.Inf(1)
+	}
+	if p < 0.5 {
+		return -studentTQuantile(1-p, df)
+	}
+	// The CDF is monotonic, so a bisection search is simple and accurate enough.  Grow the upper bound until it is
+	// past p first, since t distributions with few degrees of freedom have very long tails.
+	low, high := 0.0, 1.0
+	for studentTCDF(high, df) < p {
+		low = high
+		high *= 2
+	}
+	for i := 0; i < 200 && high-low > 1e-12*high; i++ {
+		mid := (low + high) / 2
+		if studentTCDF(mid, df) < p {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return (low + high) / 2
+}
+
+// regularizedIncompleteBeta returns I_x(a, b), the regularized incomplete beta function
+func regularizedIncompleteBeta(x float64, a float64, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	lgammaA, _ := math.Lgamma(a)
+	lgammaB, _ := math.Lgamma(b)
+	lgammaAB, _ := math.Lgamma(a + b)
+	front := math.Exp(lgammaAB - lgammaA - lgammaB + a*math.Log(x) + b*math.Log(1-x))
+	// The continued fraction converges quickly only for x < (a+1)/(a+b+2).  Use the symmetry
+	// I_x(a, b) = 1 - I_(1-x)(b, a) for the other side.
+	if x < (a+1)/(a+b+2) {
+		return front * betaContinuedFraction(x, a, b) / a
+	}
+	return 1 - front*betaContinuedFraction(1-x, b, a)/b
+}
+
+// betaContinuedFraction evaluates the continued fraction of the incomplete beta function with the modified Lentz
+// method.  See Numerical Recipes section 6.4.
+func betaContinuedFraction(x float64, a float64, b float64) float64 {
+	const tiny = 1e-300
+	const epsilon = 1e-15
+	c := 1.0
+	d := 1 - (a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	ret := d
+	for m := 1; m <= 1000; m++ {
+		fm := float64(m)
+		// Even step of the recurrence
+		numerator := fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm))
+		d = 1 + numerator*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + numerator/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		ret *= d * c
+		// Odd step of the recurrence
+		numerator = -(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1))
+		d = 1 + numerator*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + numerator/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		ret *= delta
+		if math.Abs(delta-1) < epsilon {
+			break
+		}
+	}
+	return ret
+}
+
+// geomean returns the geometric mean of values, or NaN if there are none or any are not positive
+func geomean(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	sum := 0.0
+	for _, v := range values {
+		if v <= 0 {
+			return math.NaN()
+		}
+		sum += math.Log(v)
+	}
+	return math.Exp(sum / float64(len(values)))
+}
+
+// maxExactMannWhitneySamples is the largest total number of samples mannWhitneyUTest computes the exact distribution
+// of U for.  Larger samples use the normal approximation.
+const maxExactMannWhitneySamples = 50
+
+// mannWhitneyUTest returns the two sided p-value of the Mann-Whitney U test that x and y come from the same
+// distribution.  Returns NaN if either x or y is empty.
+func mannWhitneyUTest(x []float64, y []float64) float64 {
+	n1, n2 := len(x), len(y)
+	if n1 == 0 || n2 == 0 {
+		return math.NaN()
+	}
+	ranks, tieCorrection := rank(append(append(make([]float64, 0, n1+n2), x...), y...))
+	rankSum := 0.0
+	for _, r := range ranks[:n1] {
+		rankSum += r
+	}
+	u := rankSum - float64(n1*(n1+1))/2
+	if tieCorrection == 0 && n1+n2 <= maxExactMannWhitneySamples {
+		return mannWhitneyExactP(n1, n2, u)
+	}
+	n := float64(n1 + n2)
+	mu := float64(n1*n2) / 2
+	sigma := math.Sqrt(float64(n1*n2) / 12 * ((n + 1) - tieCorrection/(n*(n-1))))
+	if sigma == 0 {
+		return 1
+	}
+	// Continuity correction, since U is discrete
+	z := math.Max(math.Abs(u-mu)-0.5, 0) / sigma
+	return math.Min(1, math.Erfc(z/math.Sqrt2))
+}
+
+// mannWhitneyExactP returns the exact two sided p-value of the U statistic u, assuming there are no ties
+func mannWhitneyExactP(n1 int, n2 int, u float64) float64 {
+	// counts[i][j][k] would be the number of orderings of i x values and j y values with U == k.  Only the table for
+	// the current i is kept: counts[j][k].  The recurrence is f(i, j, k) = f(i-1, j, k-j) + f(i, j-1, k), splitting
+	// on whether the largest value is from x or from y.
+	maxU := n1 * n2
+	prev := make([][]float64, n2+1)
+	for j := range prev {
+		prev[j] = make([]float64, maxU+1)
+		// With no x values, U is always zero
+		prev[j][0] = 1
+	}
+	for i := 1; i <= n1; i++ {
+		cur := make([][]float64, n2+1)
+		for j := range cur {
+			cur[j] = make([]float64, maxU+1)
+			for k := 0; k <= i*j; k++ {
+				if k-j >= 0 {
+					cur[j][k] += prev[j][k-j]
+				}
+				if j > 0 {
+					cur[j][k] += cur[j-1][k]
+				}
+			}
+		}
+		prev = cur
+	}
+	counts := prev[n2]
+	total, atMost, atLeast := 0.0, 0.0, 0.0
+	for k, c := range counts {
+		total += c
+		if float64(k) <= u {
+			atMost += c
+		}
+		if float64(k) >= u {
+			atLeast += c
+		}
+	}
+	return math.Min(1, 2*math.Min(atMost, atLeast)/total)
+}
+
+// rank returns the rank, starting at 1, of each value.  Tied values get the average of the ranks they span.  Also
+// returns the sum of t^3-t over every group of t tied values, which is zero if there are no ties.
+func rank(values []float64) ([]float64, float64) {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return values[order[i]] < values[order[j]]
+	})
+	ranks := make([]float64, len(values))
+	tieCorrection := 0.0
+	for start := 0; start < len(order); {
+		end := start + 1
+		for end < len(order) && values[order[end]] == values[order[start]] {
+			end++
+		}
+		averageRank := float64(start+end+1) / 2
+		for i := start; i < end; i++ {
+			ranks[order[i]] = averageRank
+		}
+		t := float64(end - start)
+		tieCorrection += t*t*t - t
+		start = end
+	}
+	return ranks, tieCorrection
+}
+
+// welchTTest returns the two sided p-value of Welch's t-test that x and y have the same mean.  Returns NaN if either x
+// or y has fewer than two values.
+func welchTTest(x []float64, y []float64) float64 {
+	n1, n2 := float64(len(x)), float64(len(y))
+	if n1 < 2 || n2 < 2 {
+		return math.NaN()
+	}
+	se1, se2 := variance(x)/n1, variance(y)/n2
+	diff := mean(x) - mean(y)
+	if se1+se2 == 0 {
+		// Without any variance, the means are either exactly the same or certainly different
+		if diff == 0 {
+			return 1
+		}
+		return 0
+	}
+	t := diff / math.Sqrt(se1+se2)
+	// Welch–Satterthwaite equation for the degrees of freedom
+	df := (se1 + se2) * (se1 + se2) / (se1*se1/(n1-1) + se2*se2/(n2-1))
+	return math.Min(1, 2*studentTCDF(-math.Abs(t), df))
+}
+
+// mannWhitneyMinP returns the smallest two sided p-value the Mann-Whitney U test can return for samples of size n1 and
+// n2.  This happens when every value of one sample is smaller than every value of the other.
+func mannWhitneyMinP(n1 int, n2 int) float64 {
+	// There are C(n1+n2, n1) orderings of the samples, and the two fully separated ones are the most extreme
+	orderings := 1.0
+	for i := 1; i <= n1; i++ {
+		orderings = orderings * float64(n2+i) / float64(i)
+	}
+	return math.Min(1, 2/orderings)
+}
@@ -0,0 +1,81 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMedian(t *testing.T) {
+	require.True(t, math.IsNaN(median(nil)))
+	require.Equal(t, 2.0, median([]float64{3, 1, 2}))
+	require.Equal(t, 2.5, median([]float64{4, 1, 3, 2}))
+}
+
+func TestVariance(t *testing.T) {
+	require.Equal(t, 0.0, variance([]float64{5}))
+	require.InDelta(t, 2.5, variance([]float64{1, 2, 3, 4, 5}), 1e-12)
+}
+
+func TestQuantile(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4, 5}
+	require.Equal(t, 1.0, quantile(sorted, 0))
+	require.Equal(t, 2.0, quantile(sorted, 0.25))
+	require.Equal(t, 5.0, quantile(sorted, 1))
+	require.Equal(t, 1.5, quantile([]float64{1, 2}, 0.5))
+}
+
+func TestStudentTCDF(t *testing.T) {
+	require.InDelta(t, 0.5, studentTCDF(0, 3), 1e-12)
+	require.InDelta(t, 0.975, studentTCDF(2.262157, 9), 1e-6)
+	require.InDelta(t, 0.025, studentTCDF(-2.262157, 9), 1e-6)
+	require.InDelta(t, 0.75, studentTCDF(1, 1), 1e-12)
+}
+
+func TestStudentTQuantile(t *testing.T) {
+	require.InDelta(t, 12.706205, studentTQuantile(0.975, 1), 1e-5)
+	require.InDelta(t, 2.262157, studentTQuantile(0.975, 9), 1e-6)
+	require.InDelta(t, -2.262157, studentTQuantile(0.025, 9), 1e-6)
+	require.InDelta(t, 1.959964, studentTQuantile(0.975, 1e6), 1e-4)
+}
+
+func TestRank(t *testing.T) {
+	ranks, tieCorrection := rank([]float64{3, 1, 2, 2})
+	require.Equal(t, []float64{4, 1, 2.5, 2.5}, ranks)
+	require.Equal(t, 6.0, tieCorrection)
+}
+
+func TestMannWhitneyUTest(t *testing.T) {
+	require.True(t, math.IsNaN(mannWhitneyUTest(nil, []float64{1})))
+	// Fully separated samples have one ordering out of C(10, 5) = 252 on each side
+	require.InDelta(t, 2.0/252, mannWhitneyUTest([]float64{1, 2, 3, 4, 5}, []float64{6, 7, 8, 9, 10}), 1e-12)
+	require.InDelta(t, 2.0/252, mannWhitneyUTest([]float64{6, 7, 8, 9, 10}, []float64{1, 2, 3, 4, 5}), 1e-12)
+	// Three samples each can never be significant at 0.05
+	require.InDelta(t, 0.1, mannWhitneyUTest([]float64{1, 2, 3}, []float64{4, 5, 6}), 1e-12)
+	require.Equal(t, 1.0, mannWhitneyUTest([]float64{1, 4}, []float64{2, 3}))
+	// Ties use the normal approximation
+	require.InDelta(t, 0.136658, mannWhitneyUTest([]float64{1, 2, 2, 3}, []float64{2, 3, 4, 5}), 1e-6)
+	require.Equal(t, 1.0, mannWhitneyUTest([]float64{1, 1}, []float64{1, 1}))
+}
+
+func TestGeomean(t *testing.T) {
+	require.InDelta(t, 4.0, geomean([]float64{2, 8}), 1e-12)
+	require.True(t, math.IsNaN(geomean([]float64{2, 0})))
+	require.True(t, math.IsNaN(geomean(nil)))
+}
+
+func TestWelchTTest(t *testing.T) {
+	require.True(t, math.IsNaN(welchTTest([]float64{1}, []float64{1, 2})))
+	require.InDelta(t, 0.0010528, welchTTest([]float64{1, 2, 3, 4, 5}, []float64{6, 7, 8, 9, 10}), 1e-6)
+	require.InDelta(t, 1, welchTTest([]float64{1, 2, 3}, []float64{1, 2, 3}), 1e-12)
+	require.Equal(t, 1.0, welchTTest([]float64{2, 2}, []float64{2, 2}))
+	require.Equal(t, 0.0, welchTTest([]float64{2, 2}, []float64{3, 3}))
+}
+
+func TestMannWhitneyMinP(t *testing.T) {
+	require.InDelta(t, 2.0/252, mannWhitneyMinP(5, 5), 1e-12)
+	require.InDelta(t, 0.1, mannWhitneyMinP(3, 3), 1e-12)
+	require.Equal(t, 1.0, mannWhitneyMinP(1, 1))
+	require.Equal(t, 1.0, mannWhitneyMinP(0, 3))
+}
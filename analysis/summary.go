@@ -0,0 +1,101 @@
+package analysis
+
+import (
+	"math"
+
+	"github.com/cep21/benchparse"
+)
+
+// DefaultConfidence is the confidence level a Summarizer uses if none is set
+const DefaultConfidence = 0.95
+
+// Summary describes the values of a single unit across every result of a Group
+type Summary struct {
+	// Group the values came from
+	Group *Group
+	// Unit of the values
+	Unit string
+	// Samples are the values of Unit, in the order of the results of Group
+	Samples []float64
+	// N is the number of samples
+	N int
+	// Mean is the arithmetic mean of the samples
+	Mean float64
+	// Median is the median of the samples
+	Median float64
+	// Min is the smallest sample
+	Min float64
+	// Max is the largest sample
+	Max float64
+	// StdDev is the sample standard deviation.  It is zero if N < 2.
+	StdDev float64
+	// Confidence is the confidence level of the interval [CILow, CIHigh]
+	Confidence float64
+	// CILow and CIHigh are the confidence interval of the mean, using Student's t distribution.  They are NaN if N < 2.
+	CILow  float64
+	CIHigh float64
+}
+
+// Summarizer computes summaries of grouped benchmark results
+type Summarizer struct {
+	// Confidence is the confidence level, between 0 and 1, of the confidence interval of each Summary.  If zero,
+	// DefaultConfidence is used.
+	Confidence float64
+}
+
+// Summarize groups every result of run and summarizes each unit of each group.  Summaries are ordered by group, in
+// the order of GroupResults, then unit, in the order of Group.Units.
+func Summarize(run *benchparse.Run) []Summary {
+	return Summarizer{}.Summarize(run)
+}
+
+// Summarize groups every result of run and summarizes each unit of each group.  Summaries are ordered by group, in
+// the order of GroupResults, then unit, in the order of Group.Units.
+func (s Summarizer) Summarize(run *benchparse.Run) []Summary {
+	var ret []Summary
+	for _, g := range GroupResults(run.Results) {
+		for _, unit := range g.Units() {
+			ret = append(ret, s.SummarizeGroup(g, unit))
+		}
+	}
+	return ret
+}
+
+// SummarizeGroup summarizes the values of unit across the results of g
+func (s Summarizer) SummarizeGroup(g *Group, unit string) Summary {
+	return s.summarize(g, unit, g.Values(unit))
+}
+
+// summarize computes the Summary of samples
+func (s Summarizer) summarize(g *Group, unit string, samples []float64) Summary {
+	confidence := s.Confidence
+	if confidence == 0 {
+		confidence = DefaultConfidence
+	}
+	ret := Summary{
+		Group:      g,
+		Unit:       unit,
+		Samples:    samples,
+		N:          len(samples),
+		Mean:       mean(samples),
+		Median:     median(samples),
+		Min:        math.NaN(),
+		Max:        math.NaN(),
+		StdDev:     math.Sqrt(variance(samples)),
+		Confidence: confidence,
+		CILow:      math.NaN(),
+		CIHigh:     math.NaN(),
+	}
+	if len(samples) > 0 {
+		sorted := sortedCopy(samples)
+		ret.Min = sorted[0]
+		ret.Max = sorted[len(sorted)-1]
+	}
+	if len(samples) >= 2 {
+		t := studentTQuantile((1+confidence)/2, float64(len(samples)-1))
+		halfWidth := t * ret.StdDev / math.Sqrt(float64(len(samples)))
+		ret.CILow = ret.Mean - halfWidth
+		ret.CIHigh = ret.Mean + halfWidth
+	}
+	return ret
+}
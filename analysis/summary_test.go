@@ -0,0 +1,66 @@
+package analysis
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/cep21/benchparse"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarize(t *testing.T) {
+	d := benchparse.Decoder{}
+	run, err := d.Decode(strings.NewReader(`BenchmarkBob-8 100 10 ns/op 1 B/op
+BenchmarkBob-8 100 12 ns/op 1 B/op
+BenchmarkBob-8 100 11 ns/op 1 B/op
+BenchmarkBob-8 100 14 ns/op 1 B/op
+BenchmarkBob-8 100 13 ns/op 1 B/op
+BenchmarkJohn-8 100 7 ns/op
+`))
+	require.NoError(t, err)
+	summaries := Summarize(run)
+	require.Len(t, summaries, 3)
+
+	s := summaries[0]
+	require.Equal(t, "Bob", s.Group.Name())
+	require.Equal(t, "ns/op", s.Unit)
+	require.Equal(t, []float64{10, 12, 11, 14, 13}, s.Samples)
+	require.Equal(t, 5, s.N)
+	require.Equal(t, 12.0, s.Mean)
+	require.Equal(t, 12.0, s.Median)
+	require.Equal(t, 10.0, s.Min)
+	require.Equal(t, 14.0, s.Max)
+	require.InDelta(t, math.Sqrt(2.5), s.StdDev, 1e-12)
+	require.Equal(t, DefaultConfidence, s.Confidence)
+	// t(0.975, 4) = 2.776445
+	require.InDelta(t, 12-2.776445*math.Sqrt(2.5)/math.Sqrt(5), s.CILow, 1e-5)
+	require.InDelta(t, 12+2.776445*math.Sqrt(2.5)/math.Sqrt(5), s.CIHigh, 1e-5)
+
+	s = summaries[1]
+	require.Equal(t, "B/op", s.Unit)
+	require.Equal(t, 0.0, s.StdDev)
+	require.Equal(t, 1.0, s.CILow)
+	require.Equal(t, 1.0, s.CIHigh)
+
+	s = summaries[2]
+	require.Equal(t, "John", s.Group.Name())
+	require.Equal(t, 1, s.N)
+	require.Equal(t, 7.0, s.Mean)
+	require.True(t, math.IsNaN(s.CILow))
+	require.True(t, math.IsNaN(s.CIHigh))
+}
+
+func TestSummarizer_Confidence(t *testing.T) {
+	g := &Group{
+		Results: []benchparse.BenchmarkResult{
+			{Name: "BenchmarkBob", Values: []benchparse.ValueUnitPair{{Value: 1, Unit: "ns/op"}}},
+			{Name: "BenchmarkBob", Values: []benchparse.ValueUnitPair{{Value: 3, Unit: "ns/op"}}},
+		},
+	}
+	s := Summarizer{Confidence: 0.5}.SummarizeGroup(g, "ns/op")
+	require.Equal(t, 0.5, s.Confidence)
+	// t(0.75, 1) = 1
+	require.InDelta(t, 1, s.CILow, 1e-9)
+	require.InDelta(t, 3, s.CIHigh, 1e-9)
+}
@@ -3,6 +3,7 @@ package benchparse
 import (
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 )
 
@@ -19,6 +20,44 @@ const (
 type Run struct {
 	// Results are the result of running each benchmark
 	Results []BenchmarkResult
+	// Units is the unit metadata of every "Unit" line in the run, including lines after the last result.  It is nil
+	// if the run had no unit metadata.
+	Units *UnitMetadata
+	// Packages are the packages go test reported on, in order.  Results of a package are always contiguous in Results.
+	// It is empty if the input had no go test framing lines.
+	Packages []PackageSection
+	// raw is the original text of the run if it was decoded by a lossless Decoder
+	raw *rawRun
+}
+
+// PackageStatus is the final status of a tested package
+type PackageStatus string
+
+const (
+	// PackageStatusOK is a package whose tests and benchmarks passed
+	PackageStatusOK PackageStatus = "ok"
+	// PackageStatusFail is a package whose tests or benchmarks failed
+	PackageStatusFail PackageStatus = "FAIL"
+	// PackageStatusIncomplete is a package whose output ended before go test printed its status.  This usually means
+	// the output was truncated.
+	PackageStatusIncomplete PackageStatus = "incomplete"
+)
+
+// PackageSection is the part of a Run that came from testing a single package, ending in the "ok" or "FAIL" line go
+// test prints for the package.
+type PackageSection struct {
+	// Package is the import path of the package.  For incomplete packages, it is the value of the "pkg" configuration
+	// key, which go test prints before running benchmarks.
+	Package string
+	// Status is the final status of the package
+	Status PackageStatus
+	// Elapsed is the wall time go test reported for the package.  It is zero if the time was not reported.
+	Elapsed time.Duration
+	// Cached is true if go test reused a cached result for the package
+	Cached bool
+	// ResultsStart and ResultsEnd are the range [ResultsStart, ResultsEnd) of Run.Results produced by the package
+	ResultsStart int
+	ResultsEnd   int
 }
 
 // BenchmarkResult is a single line of a benchmark result
@@ -34,6 +73,103 @@ type BenchmarkResult struct {
 	// data by pointing to the same OrderedStringStringMap.  Do not modify the Configuration of any one BenchmarkResult
 	// unless you are **sure** they do not share the same OrderedStringStringMap data's backing.
 	Configuration *OrderedStringStringMap
+	// Units is the unit metadata from "Unit" lines that came before this result.  It is nil if there were none.  Like
+	// Configuration, multiple BenchmarkResult may share the same UnitMetadata.
+	Units *UnitMetadata
+	// raw is the original text of this result if it was decoded by a lossless Decoder
+	raw *rawResult
+	// procs is set if a Decoder with a ProcsKey added the key to Configuration
+	procs *procsConfiguration
+}
+
+// procsConfiguration is a configuration the input set, and a copy of it with the ProcsKey a Decoder added
+type procsConfiguration struct {
+	input     *OrderedStringStringMap
+	withProcs *OrderedStringStringMap
+	// value is the value of the ProcsKey
+	value string
+}
+
+// Clone makes a deep copy of the run.  Results that share a Configuration or Units in r share the same copy in the
+// returned run, and a configuration key a Decoder added with ProcsKey is still not written by Encoder.  The Clone of nil
+// is nil.
+func (r *Run) Clone() *Run {
+	if r == nil {
+		return nil
+	}
+	c := runCloner{
+		configurations: make(map[*OrderedStringStringMap]*OrderedStringStringMap),
+		units:          make(map[*UnitMetadata]*UnitMetadata),
+		procs:          make(map[*procsConfiguration]*procsConfiguration),
+	}
+	ret := &Run{
+		Results:  make([]BenchmarkResult, 0, len(r.Results)),
+		Units:    c.cloneUnits(r.Units),
+		Packages: append([]PackageSection(nil), r.Packages...),
+		raw:      r.raw,
+	}
+	for _, result := range r.Results {
+		ret.Results = append(ret.Results, c.cloneResult(result))
+	}
+	return ret
+}
+
+// runCloner remembers the copies Run.Clone made, so shared objects stay shared
+type runCloner struct {
+	configurations map[*OrderedStringStringMap]*OrderedStringStringMap
+	units          map[*UnitMetadata]*UnitMetadata
+	procs          map[*procsConfiguration]*procsConfiguration
+}
+
+func (c runCloner) cloneResult(r BenchmarkResult) BenchmarkResult {
+	r.Values = append([]ValueUnitPair(nil), r.Values...)
+	r.Units = c.cloneUnits(r.Units)
+	if r.procs != nil && r.Configuration == r.procs.withProcs {
+		procs, exists := c.procs[r.procs]
+		if !exists {
+			procs = &procsConfiguration{
+				input:     c.cloneConfiguration(r.procs.input),
+				withProcs: c.cloneConfiguration(r.procs.withProcs),
+				value:     r.procs.value,
+			}
+			c.procs[r.procs] = procs
+		}
+		r.procs = procs
+		r.Configuration = procs.withProcs
+		return r
+	}
+	r.procs = nil
+	r.Configuration = c.cloneConfiguration(r.Configuration)
+	return r
+}
+
+func (c runCloner) cloneConfiguration(o *OrderedStringStringMap) *OrderedStringStringMap {
+	if o == nil {
+		return nil
+	}
+	if _, exists := c.configurations[o]; !exists {
+		c.configurations[o] = o.Clone()
+	}
+	return c.configurations[o]
+}
+
+func (c runCloner) cloneUnits(u *UnitMetadata) *UnitMetadata {
+	if u == nil {
+		return nil
+	}
+	if _, exists := c.units[u]; !exists {
+		c.units[u] = u.Clone()
+	}
+	return c.units[u]
+}
+
+// encodedConfiguration returns the configuration Encoder writes for the result.  That is Configuration, except
+// without a ProcsKey the Decoder added, unless Configuration was replaced since.
+func (b BenchmarkResult) encodedConfiguration() *OrderedStringStringMap {
+	if b.procs != nil && b.Configuration == b.procs.withProcs {
+		return b.procs.input
+	}
+	return b.Configuration
 }
 
 // ValueUnitPair is the result of one (of possibly many) benchmark numeric computations
@@ -45,7 +181,12 @@ type ValueUnitPair struct {
 }
 
 func (b ValueUnitPair) String() string {
-	return strconv.FormatFloat(b.Value, 'f', -1, 64) + " " + b.Unit
+	return formatValue(b.Value) + " " + b.Unit
+}
+
+// formatValue formats a benchmark value with the fewest digits needed to decode it back exactly
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
 }
 
 // NameAsKeyValue parses the name of the benchmark as a subtest/subbench split by / assuming you use
@@ -71,6 +212,76 @@ func (b BenchmarkResult) NameAsKeyValue() *OrderedStringStringMap {
 	return &ret
 }
 
+// BenchmarkName is the decomposition of a benchmark's name into the parts the testing package builds it from.  For
+// example, "BenchmarkDecode/text=digits/level=speed/size=1e4-8" has the Base "Decode/text=digits/level=speed/size=1e4-8",
+// the Function "Decode", the SubBenchmarks "text=digits", "level=speed" and "size=1e4", and Procs 8.
+type BenchmarkName struct {
+	// Base is the full name without the leading "Benchmark" prefix.  Nothing else is removed.
+	Base string
+	// Function is the name of the top level benchmark function without the "Benchmark" prefix.  It is empty for
+	// a benchmark named just "Benchmark".
+	Function string
+	// SubBenchmarks are the / separated sub benchmark names that follow Function.  The -N suffix, if any, is removed
+	// from the last element.
+	SubBenchmarks []string
+	// Procs is the GOMAXPROCS value parsed from a trailing "-N" on the name.  It is only valid if HasProcs is true.
+	Procs int
+	// HasProcs is true if the name ended with a "-N" suffix.
+	HasProcs bool
+}
+
+// ParsedName decomposes the name of the benchmark into its base name, top level function, sub benchmarks and the
+// "-N" GOMAXPROCS suffix.  Names that do not begin with "Benchmark" are decomposed as if the prefix was already
+// removed.
+func (b BenchmarkResult) ParsedName() BenchmarkName {
+	base := b.BaseName()
+	withoutProcs, procs, hasProcs := splitProcs(base)
+	nameParts := strings.Split(withoutProcs, "/")
+	return BenchmarkName{
+		Base:          base,
+		Function:      nameParts[0],
+		SubBenchmarks: nameParts[1:],
+		Procs:         procs,
+		HasProcs:      hasProcs,
+	}
+}
+
+// BaseName returns the name of the benchmark without the "Benchmark" prefix.  For example, the base name of
+// "BenchmarkDecode/text=digits-8" is "Decode/text=digits-8".
+func (b BenchmarkResult) BaseName() string {
+	return strings.TrimPrefix(b.Name, "Benchmark")
+}
+
+// Procs returns the GOMAXPROCS value the benchmark ran with, parsed from the "-N" suffix the testing package appends
+// to benchmark names.  Returns false if the name has no such suffix.
+func (b BenchmarkResult) Procs() (int, bool) {
+	_, procs, hasProcs := splitProcs(b.Name)
+	return procs, hasProcs
+}
+
+// splitProcs splits a trailing "-N" GOMAXPROCS suffix from name.  If name does not end in "-(\d+)", it is returned
+// unmodified with hasProcs false.
+func splitProcs(name string) (withoutProcs string, procs int, hasProcs bool) {
+	lastDash := strings.LastIndex(name, "-")
+	if lastDash == -1 {
+		// No "-" means it doesn't match the pattern -N
+		return name, 0, false
+	}
+	partAfterDash := name[lastDash+1:]
+	if len(partAfterDash) == 0 || strings.IndexFunc(partAfterDash, func(r rune) bool {
+		return !unicode.IsNumber(r)
+	}) != -1 {
+		// Anything after the last - that isn't a number doesn't match the pattern either.
+		return name, 0, false
+	}
+	procs, err := strconv.Atoi(partAfterDash)
+	if err != nil {
+		// unicode.IsNumber allows numbers that strconv does not, like superscripts.  Those aren't -N either.
+		return name, 0, false
+	}
+	return name[0:lastDash], procs, true
+}
+
 // AllKeyValuePairs returns the combination of the configuration key/value pairs followed by the benchmark name's
 // key/value pairs.  It handles the special case of -N at the end of the last benchmark key/value pair by removing
 // anything matching "-(\d+)" from the last key/value pair of the benchmark name.
@@ -87,22 +298,8 @@ func (b BenchmarkResult) AllKeyValuePairs() *OrderedStringStringMap {
 			ret.add(p, namePart.Contents[p])
 			continue
 		}
-		lastValue := namePart.Contents[p]
-		lastDash := strings.LastIndex(lastValue, "-")
-		if lastDash == -1 {
-			// No "-" means it doesn't match the pattern -N
-			ret.add(p, namePart.Contents[p])
-			continue
-		}
-		partAfterDash := lastValue[lastDash+1:]
-		if len(partAfterDash) == 0 || strings.IndexFunc(partAfterDash, func(r rune) bool {
-			return !unicode.IsNumber(r)
-		}) != -1 {
-			// Anything after the last - that isn't a number doesn't match the pattern either.
-			ret.add(p, namePart.Contents[p])
-			continue
-		}
-		ret.add(p, lastValue[0:lastDash])
+		lastValue, _, _ := splitProcs(namePart.Contents[p])
+		ret.add(p, lastValue)
 	}
 	return &ret
 }
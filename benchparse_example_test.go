@@ -51,6 +51,7 @@ BenchmarkDecode/text=digits/level=speed/size=1e4-8   	     100	    154125 ns/op
 	fmt.Println("The number of results:", len(run.Results))
 	fmt.Println("Git commit:", run.Results[0].Configuration.Contents["commit"])
 	fmt.Println("Name of first benchmark:", run.Results[0].Name)
+	fmt.Println("Base name of first result:", run.Results[0].BaseName())
 	fmt.Println("Level config of first result:", run.Results[0].NameAsKeyValue().Contents["level"])
 	testRunTime, _ := run.Results[0].ValueByUnit(benchparse.UnitRuntime)
 	fmt.Println("Runtime of first result:", testRunTime)
@@ -59,6 +60,7 @@ BenchmarkDecode/text=digits/level=speed/size=1e4-8   	     100	    154125 ns/op
 	// Output: The number of results: 1
 	// Git commit: 7cd9055
 	// Name of first benchmark: BenchmarkDecode/text=digits/level=speed/size=1e4-8
+	// Base name of first result: Decode/text=digits/level=speed/size=1e4-8
 	// Level config of first result: speed
 	// Runtime of first result: 154125
 	// Does unit misses/op exist in the first run: false
@@ -72,6 +74,19 @@ func ExampleBenchmarkResult_NameAsKeyValue() {
 	// Output: digits
 }
 
+func ExampleBenchmarkResult_ParsedName() {
+	b := benchparse.BenchmarkResult{
+		Name: "BenchmarkDecode/text=digits/level=speed/size=1e4-8",
+	}
+	name := b.ParsedName()
+	fmt.Println(name.Function)
+	fmt.Println(name.SubBenchmarks)
+	fmt.Println(name.Procs, name.HasProcs)
+	// Output: Decode
+	// [text=digits level=speed size=1e4]
+	// 8 true
+}
+
 func ExampleBenchmarkResult_AllKeyValuePairs() {
 	b := benchparse.BenchmarkResult{
 		Configuration: &benchparse.OrderedStringStringMap{
@@ -118,6 +133,35 @@ BenchmarkDecode/text=digits/level=speed/size=1e4-8   	     100	    154125 ns/op
 	// commit of second run ab322f4
 }
 
+func ExampleDecoder_Decode_lineErrors() {
+	d := benchparse.Decoder{
+		OnLineError: func(err *benchparse.LineError) {
+			fmt.Println("skipped line", err.Line, "because", err.Err)
+		},
+	}
+	run, err := d.Decode(strings.NewReader(`BenchmarkDecode   	     100	    154125 ns/op
+BenchmarkEncode   	     100	    154125 ns/op 64.88
+`))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(len(run.Results))
+	// Output: skipped line 2 because invalid BenchmarkResult: expect even number of fields
+	// 1
+}
+
+func ExampleTest2JSONDecoder_Decode() {
+	d := benchparse.Test2JSONDecoder{}
+	run, err := d.Decode(strings.NewReader(`{"Action":"output","Package":"github.com/cep21/benchparse","Output":"BenchmarkDecode-8   \t"}
+{"Action":"output","Package":"github.com/cep21/benchparse","Output":"     100\t    154125 ns/op\n"}
+`))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(run.Results[0].Name, run.Results[0].Configuration.Contents["pkg"])
+	// Output: BenchmarkDecode-8 github.com/cep21/benchparse
+}
+
 func ExampleOrderedStringStringMap() {
 	d := benchparse.Decoder{}
 	run, err := d.Decode(strings.NewReader(`
@@ -3,8 +3,10 @@ package benchparse
 import (
 	"bytes"
 	"context"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -25,6 +27,134 @@ func TestDecoder_Decode(t *testing.T) {
 		require.Len(t, run.Results[0].Configuration.Order, 1)
 		require.Len(t, run.Results[1].Configuration.Order, 1)
 	})
+	t.Run("packages", func(t *testing.T) {
+		d := Decoder{}
+		run, err := d.Decode(strings.NewReader(goTestExample))
+		require.NoError(t, err)
+		require.Len(t, run.Results, 4)
+		require.Equal(t, []PackageSection{
+			{
+				Package:      "github.com/cep21/a",
+				Status:       PackageStatusOK,
+				Elapsed:      1302 * time.Millisecond,
+				ResultsStart: 0,
+				ResultsEnd:   2,
+			},
+			{
+				Package:      "github.com/cep21/b",
+				Status:       PackageStatusFail,
+				Elapsed:      510 * time.Millisecond,
+				ResultsStart: 2,
+				ResultsEnd:   3,
+			},
+			{
+				Package:      "github.com/cep21/c",
+				Status:       PackageStatusOK,
+				Cached:       true,
+				ResultsStart: 3,
+				ResultsEnd:   3,
+			},
+			{
+				Package:      "github.com/cep21/d",
+				Status:       PackageStatusIncomplete,
+				ResultsStart: 3,
+				ResultsEnd:   4,
+			},
+		}, run.Packages)
+	})
+	t.Run("noframing", func(t *testing.T) {
+		d := Decoder{}
+		run, err := d.Decode(strings.NewReader("commit: a\nBenchmarkX 100 1 ns/op\n"))
+		require.NoError(t, err)
+		require.Empty(t, run.Packages)
+		run, err = d.Decode(strings.NewReader("BenchmarkX 100 1 ns/op\nok  \tgithub.com/cep21/a\t1.2s\nBenchmarkY 100 1 ns/op\n"))
+		require.NoError(t, err)
+		require.Len(t, run.Packages, 1)
+		require.Equal(t, 1, run.Packages[0].ResultsEnd)
+	})
+	t.Run("binarystatus", func(t *testing.T) {
+		d := Decoder{}
+		run, err := d.Decode(strings.NewReader("pkg: github.com/cep21/a\nBenchmarkFoo-8 100 3.24 ns/op\nPASS\n"))
+		require.NoError(t, err)
+		require.Equal(t, []PackageSection{
+			{
+				Package:      "github.com/cep21/a",
+				Status:       PackageStatusOK,
+				ResultsStart: 0,
+				ResultsEnd:   1,
+			},
+		}, run.Packages)
+	})
+	t.Run("units", func(t *testing.T) {
+		d := Decoder{}
+		run, err := d.Decode(strings.NewReader(`Unit ns/op assume=exact
+BenchmarkBob 100 10 ns/op 3 misses/op
+Unit misses/op better=lower
+Unit ns/op better=lower
+BenchmarkBob 100 10 ns/op 3 misses/op
+BenchmarkBob 100 10 ns/op 3 misses/op
+Unit hits/op better=higher
+`))
+		require.NoError(t, err)
+		require.Len(t, run.Results, 3)
+		require.Equal(t, []string{"ns/op"}, run.Results[0].Units.Order)
+		_, exists := run.Results[0].Units.Lookup("ns/op", "better")
+		require.False(t, exists)
+		better, exists := run.Results[1].Units.Lookup("ns/op", "better")
+		require.True(t, exists)
+		require.Equal(t, "lower", better)
+		assume, _ := run.Results[1].Units.Lookup("ns/op", "assume")
+		require.Equal(t, "exact", assume)
+		require.True(t, run.Results[1].Units == run.Results[2].Units)
+		require.Equal(t, []string{"ns/op", "misses/op", "hits/op"}, run.Units.Order)
+		better, _ = run.Units.Lookup("hits/op", "better")
+		require.Equal(t, "higher", better)
+	})
+	t.Run("procs", func(t *testing.T) {
+		d := Decoder{ProcsKey: "procs"}
+		run, err := d.Decode(strings.NewReader(`commit: 7cd9055
+BenchmarkBob-1 100 10 ns/op
+BenchmarkBob-1 100 10 ns/op
+BenchmarkBob-4 100 5 ns/op
+BenchmarkBob 100 5 ns/op
+`))
+		require.NoError(t, err)
+		require.Len(t, run.Results, 4)
+		require.Equal(t, []string{"commit", "procs"}, run.Results[0].Configuration.Order)
+		require.Equal(t, "1", run.Results[0].AllKeyValuePairs().Contents["procs"])
+		require.True(t, run.Results[0].Configuration == run.Results[1].Configuration)
+		require.Equal(t, "4", run.Results[2].AllKeyValuePairs().Contents["procs"])
+		require.Equal(t, "1", run.Results[1].AllKeyValuePairs().Contents["procs"])
+		require.Equal(t, []string{"commit"}, run.Results[3].Configuration.Order)
+	})
+	t.Run("procs_input", func(t *testing.T) {
+		d := Decoder{ProcsKey: "procs"}
+		run, err := d.Decode(strings.NewReader(`procs: many
+BenchmarkBob-4 100 5 ns/op
+BenchmarkBob 100 5 ns/op
+`))
+		require.NoError(t, err)
+		require.Equal(t, "many", run.Results[0].Configuration.Contents["procs"])
+		require.Equal(t, "many", run.Results[1].Configuration.Contents["procs"])
+	})
+	t.Run("procs_encode", func(t *testing.T) {
+		in := `commit: 7cd9055
+BenchmarkBob-1 100 10 ns/op
+BenchmarkBob-4 100 5 ns/op
+BenchmarkBob 100 5 ns/op
+commit: 7cd9056
+BenchmarkBob-4 100 5 ns/op
+`
+		for _, lossless := range []bool{false, true} {
+			d := Decoder{ProcsKey: "procs", Lossless: lossless}
+			run, err := d.Decode(strings.NewReader(in))
+			require.NoError(t, err)
+			require.Equal(t, "4", run.Results[3].Configuration.Contents["procs"])
+			var buf bytes.Buffer
+			require.NoError(t, (&Encoder{}).Encode(&buf, run))
+			require.Equal(t, in, buf.String())
+		}
+	})
 }
 
 func TestDecoder_Stream(t *testing.T) {
@@ -39,6 +169,124 @@ func TestDecoder_Stream(t *testing.T) {
 	require.Equal(t, 0, i)
 }
 
+func TestDecoder_OnUnits(t *testing.T) {
+	var units []*UnitMetadata
+	d := Decoder{OnUnits: func(u *UnitMetadata) {
+		units = append(units, u)
+	}}
+	var results []BenchmarkResult
+	require.NoError(t, d.Stream(context.Background(), strings.NewReader(`Unit ns/op better=lower
+BenchmarkA 1 10 ns/op
+Unit ns/op assume=exact
+Unit B/op better=lower
+`), func(r BenchmarkResult) {
+		results = append(results, r)
+	}))
+	require.Len(t, units, 3)
+	require.True(t, results[0].Units == units[0])
+	require.Equal(t, []string{"better"}, units[0].Contents["ns/op"].Order)
+	require.Equal(t, []string{"ns/op", "B/op"}, units[2].Order)
+	require.Equal(t, []string{"better", "assume"}, units[2].Contents["ns/op"].Order)
+}
+
+func TestUnitMetadata_Merge(t *testing.T) {
+	u := &UnitMetadata{}
+	u.Merge(nil)
+	require.Empty(t, u.Order)
+	u.Merge(&UnitMetadata{
+		Contents: map[string]*OrderedStringStringMap{
+			"ns/op": {Contents: map[string]string{"better": "lower"}, Order: []string{"better"}},
+		},
+		Order: []string{"ns/op", "B/op"},
+	})
+	u.Merge(&UnitMetadata{
+		Contents: map[string]*OrderedStringStringMap{
+			"ns/op": {Contents: map[string]string{"better": "higher", "assume": "exact"}, Order: []string{"better", "assume"}},
+		},
+		Order: []string{"ns/op"},
+	})
+	require.Equal(t, []string{"ns/op", "B/op"}, u.Order)
+	require.Equal(t, &OrderedStringStringMap{}, u.Contents["B/op"])
+	require.Equal(t, []string{"better", "assume"}, u.Contents["ns/op"].Order)
+	better, _ := u.Lookup("ns/op", "better")
+	require.Equal(t, "higher", better)
+}
+
+func TestDecoder_OnLineError(t *testing.T) {
+	var lineErrors []*LineError
+	d := Decoder{
+		OnLineError: func(err *LineError) {
+			lineErrors = append(lineErrors, err)
+		},
+	}
+	run, err := d.Decode(strings.NewReader(`commit: 7cd9055
+
+BenchmarkFoo 1.5 10 ns/op
+BenchmarkFoo 1 10 ns/op
+Akey: bob
+BenchmarkBar 1 10 ns/op 5
+Unit ns/op better
+`))
+	require.NoError(t, err)
+	require.Len(t, run.Results, 1)
+	require.Len(t, lineErrors, 4)
+
+	require.Equal(t, 3, lineErrors[0].Line)
+	require.Equal(t, "BenchmarkFoo 1.5 10 ns/op", lineErrors[0].Text)
+	require.Equal(t, LineKindBenchmarkResult, lineErrors[0].Kind)
+	numErr, ok := lineErrors[0].Unwrap().(*strconv.NumError)
+	require.True(t, ok)
+	require.Equal(t, strconv.ErrSyntax, numErr.Err)
+
+	require.Equal(t, 5, lineErrors[1].Line)
+	require.Equal(t, LineKindConfiguration, lineErrors[1].Kind)
+	require.Equal(t, ErrInvalidKeyValueLowercase, lineErrors[1].Unwrap())
+
+	require.Equal(t, 6, lineErrors[2].Line)
+	require.Equal(t, LineKindBenchmarkResult, lineErrors[2].Kind)
+	require.Equal(t, ErrEvenFields, lineErrors[2].Unwrap())
+	require.Equal(t, `line 6: invalid benchmark result line "BenchmarkBar 1 10 ns/op 5": invalid BenchmarkResult: expect even number of fields`, lineErrors[2].Error())
+
+	require.Equal(t, 7, lineErrors[3].Line)
+	require.Equal(t, LineKindUnit, lineErrors[3].Kind)
+	require.Equal(t, ErrUnitKeyValue, lineErrors[3].Unwrap())
+}
+
+func TestDecoder_Strict(t *testing.T) {
+	verifyStrictFails := func(in string, line int, expected error) func(t *testing.T) {
+		return func(t *testing.T) {
+			d := Decoder{Strict: true}
+			_, err := d.Decode(strings.NewReader(in))
+			require.Error(t, err)
+			lineErr, ok := err.(*LineError)
+			require.True(t, ok)
+			require.Equal(t, line, lineErr.Line)
+			require.Equal(t, LineKindBenchmarkResult, lineErr.Kind)
+			if expected != nil {
+				require.Equal(t, expected, lineErr.Err)
+			}
+		}
+	}
+	t.Run("case=oddfields", verifyStrictFails("BenchmarkFoo 1 10 ns/op\nBenchmarkFoo 1 10 ns/op 5\n", 2, ErrEvenFields))
+	t.Run("case=truncated", verifyStrictFails("BenchmarkFoo 1 10 ns/op\nBenchmarkFoo 1\n", 2, ErrNotEnoughFields))
+	t.Run("case=no_int_iters", verifyStrictFails("BenchmarkFoo 1.5 10 ns/op\n", 1, nil))
+	t.Run("case=no_float_value", verifyStrictFails("BenchmarkFoo 1 10b ns/op\n", 1, nil))
+	t.Run("case=justbenchmark", verifyStrictFails("Benchmark 1 10b ns/op\n", 1, nil))
+
+	verifyStrictPasses := func(in string, expectedResults int) func(t *testing.T) {
+		return func(t *testing.T) {
+			d := Decoder{Strict: true}
+			run, err := d.Decode(strings.NewReader(in))
+			require.NoError(t, err)
+			require.Len(t, run.Results, expectedResults)
+		}
+	}
+	t.Run("case=readme", verifyStrictPasses(readmeExample, 27))
+	t.Run("case=noise", verifyStrictPasses(noisyExample, 2))
+	t.Run("case=verbose", verifyStrictPasses("BenchmarkFoo\nBenchmarkFoo-8 1 10 ns/op\n", 1))
+	t.Run("case=lowercase", verifyStrictPasses("Benchmarkfoo 1 10b ns/op\n", 0))
+}
+
 func TestBenchmarkResultDecoder_decodeok(t *testing.T) {
 	verifyParses := func(line string, expected string) func(t *testing.T) {
 		return func(t *testing.T) {
@@ -63,10 +311,10 @@ func TestBenchmarkResultDecoder_decodebad(t *testing.T) {
 			require.Equal(t, expected, err)
 		}
 	}
-	t.Run("case=tooshort", verifyFails("Benchmark 1 10", errNotEnoughFields))
-	t.Run("case=badprefix", verifyFails("TestBob 1 10 ns/op", errNoPrefixBenchmark))
-	t.Run("case=noupper", verifyFails("Benchmarkbob 1 10 ns/op", errUpperAfterBench))
-	t.Run("case=oddfields", verifyFails("Benchmarkbob 1 10 ns/op 5 MB/s 10", errEvenFields))
+	t.Run("case=tooshort", verifyFails("Benchmark 1 10", ErrNotEnoughFields))
+	t.Run("case=badprefix", verifyFails("TestBob 1 10 ns/op", ErrNoPrefixBenchmark))
+	t.Run("case=noupper", verifyFails("Benchmarkbob 1 10 ns/op", ErrUpperAfterBench))
+	t.Run("case=oddfields", verifyFails("Benchmarkbob 1 10 ns/op 5 MB/s 10", ErrEvenFields))
 
 	verifyFailsSomehow := func(line string, msg string) func(t *testing.T) {
 		return func(t *testing.T) {
@@ -80,6 +328,88 @@ func TestBenchmarkResultDecoder_decodebad(t *testing.T) {
 	t.Run("case=no_float_value", verifyFailsSomehow("BenchmarkBob 1 10b ns/op", "strconv.ParseFloat: parsing \"10b\": invalid syntax"))
 }
 
+func TestUnitDecoder_decode(t *testing.T) {
+	verifyParses := func(line string, unit string, expected *OrderedStringStringMap) func(t *testing.T) {
+		return func(t *testing.T) {
+			d := unitDecoder{}
+			res, err := d.decode(line)
+			require.NoError(t, err)
+			require.Equal(t, unit, res.Unit)
+			require.Equal(t, expected, res.Metadata)
+		}
+	}
+	t.Run("case=simple", verifyParses("Unit ns/op better=lower", "ns/op", &OrderedStringStringMap{
+		Contents: map[string]string{"better": "lower"},
+		Order:    []string{"better"},
+	}))
+	t.Run("case=many", verifyParses("Unit  ns/op\tassume=exact   better=lower", "ns/op", &OrderedStringStringMap{
+		Contents: map[string]string{"assume": "exact", "better": "lower"},
+		Order:    []string{"assume", "better"},
+	}))
+	t.Run("case=equalsinvalue", verifyParses("Unit x a=b=c", "x", &OrderedStringStringMap{
+		Contents: map[string]string{"a": "b=c"},
+		Order:    []string{"a"},
+	}))
+
+	verifyFails := func(line string, expected error) func(t *testing.T) {
+		return func(t *testing.T) {
+			d := unitDecoder{}
+			_, err := d.decode(line)
+			require.Equal(t, expected, err)
+		}
+	}
+	t.Run("case=empty", verifyFails("", ErrUnitNoPrefix))
+	t.Run("case=noprefix", verifyFails("Units ns/op better=lower", ErrUnitNoPrefix))
+	t.Run("case=nometadata", verifyFails("Unit ns/op", ErrUnitNotEnoughFields))
+	t.Run("case=noequals", verifyFails("Unit ns/op better", ErrUnitKeyValue))
+	t.Run("case=emptykey", verifyFails("Unit ns/op =lower", ErrUnitKeyValue))
+}
+
+func TestPackageStatusDecoder_decode(t *testing.T) {
+	verifyParses := func(line string, expected *packageStatusLine) func(t *testing.T) {
+		return func(t *testing.T) {
+			d := packageStatusDecoder{}
+			res, err := d.decode(line)
+			require.NoError(t, err)
+			require.Equal(t, expected, res)
+		}
+	}
+	t.Run("case=pass", verifyParses("PASS", &packageStatusLine{Status: PackageStatusOK}))
+	t.Run("case=fail", verifyParses("FAIL", &packageStatusLine{Status: PackageStatusFail}))
+	t.Run("case=ok", verifyParses("ok  \tgithub.com/x/y\t12.3s", &packageStatusLine{
+		Package: "github.com/x/y",
+		Status:  PackageStatusOK,
+		Elapsed: 12300 * time.Millisecond,
+	}))
+	t.Run("case=failpkg", verifyParses("FAIL\tgithub.com/x/y\t0.010s", &packageStatusLine{
+		Package: "github.com/x/y",
+		Status:  PackageStatusFail,
+		Elapsed: 10 * time.Millisecond,
+	}))
+	t.Run("case=buildfailed", verifyParses("FAIL\tgithub.com/x/y [build failed]", &packageStatusLine{
+		Package: "github.com/x/y",
+		Status:  PackageStatusFail,
+	}))
+	t.Run("case=cached", verifyParses("ok  \tgithub.com/x/y\t(cached)", &packageStatusLine{
+		Package: "github.com/x/y",
+		Status:  PackageStatusOK,
+		Cached:  true,
+	}))
+
+	verifyFails := func(line string) func(t *testing.T) {
+		return func(t *testing.T) {
+			d := packageStatusDecoder{}
+			_, err := d.decode(line)
+			require.Equal(t, errNoPackageStatus, err)
+		}
+	}
+	t.Run("case=empty", verifyFails(""))
+	t.Run("case=ok", verifyFails("ok"))
+	t.Run("case=prose", verifyFails("ok so this happened"))
+	t.Run("case=failheader", verifyFails("--- FAIL: BenchmarkBuzz-8"))
+	t.Run("case=passing", verifyFails("PASSING"))
+}
+
 func TestKeyValueDecoder_decodeok(t *testing.T) {
 	verifyParses := func(kvLine string, key string, value string) func(t *testing.T) {
 		return func(t *testing.T) {
@@ -105,14 +435,14 @@ func TestKeyValueDecoder_decodeerror(t *testing.T) {
 			require.Equal(t, expectedErr, err)
 		}
 	}
-	t.Run("case=startspace", verifyFails(" akey: bob", errInvalidKeyValueLowercase))
-	t.Run("case=empty", verifyFails("", errInvalidKeyNoColon))
-	t.Run("case=upperstart", verifyFails("Akey: bob", errInvalidKeyValueLowercase))
-	t.Run("case=emptykey", verifyFails(": bob", errInvalidKeyValueEmpty))
-	t.Run("case=keywithspaces", verifyFails("a key: bob", errInvalidKeyValueSpaces))
-	t.Run("case=keywithtab", verifyFails("a\tkey: bob", errInvalidKeyValueSpaces))
-	t.Run("case=keywithnewline", verifyFails("a\nkey: bob", errInvalidKeyValueSpaces))
-	t.Run("case=valuewithnewline", verifyFails("akey: bo\nb", errInvalidKeyValueReturn))
+	t.Run("case=startspace", verifyFails(" akey: bob", ErrInvalidKeyValueLowercase))
+	t.Run("case=empty", verifyFails("", ErrInvalidKeyNoColon))
+	t.Run("case=upperstart", verifyFails("Akey: bob", ErrInvalidKeyValueLowercase))
+	t.Run("case=emptykey", verifyFails(": bob", ErrInvalidKeyValueEmpty))
+	t.Run("case=keywithspaces", verifyFails("a key: bob", ErrInvalidKeyValueSpaces))
+	t.Run("case=keywithtab", verifyFails("a\tkey: bob", ErrInvalidKeyValueSpaces))
+	t.Run("case=keywithnewline", verifyFails("a\nkey: bob", ErrInvalidKeyValueSpaces))
+	t.Run("case=valuewithnewline", verifyFails("akey: bo\nb", ErrInvalidKeyValueReturn))
 }
 
 func TestBenchmarkResult_AllKeyValuePairs(t *testing.T) {
@@ -222,6 +552,93 @@ func TestBenchmarkResult_AllKeyValuePairs(t *testing.T) {
 	}))
 }
 
+func TestBenchmarkResult_ParsedName(t *testing.T) {
+	verifyName := func(name string, expected BenchmarkName) func(t *testing.T) {
+		return func(t *testing.T) {
+			b := BenchmarkResult{Name: name}
+			require.Equal(t, expected, b.ParsedName())
+		}
+	}
+	t.Run("case=simple", verifyName("BenchmarkBob", BenchmarkName{
+		Base:          "Bob",
+		Function:      "Bob",
+		SubBenchmarks: []string{},
+	}))
+	t.Run("case=empty", verifyName("Benchmark", BenchmarkName{
+		Base:          "",
+		Function:      "",
+		SubBenchmarks: []string{},
+	}))
+	t.Run("case=procs", verifyName("BenchmarkBob-8", BenchmarkName{
+		Base:          "Bob-8",
+		Function:      "Bob",
+		SubBenchmarks: []string{},
+		Procs:         8,
+		HasProcs:      true,
+	}))
+	t.Run("case=readme", verifyName("BenchmarkDecode/text=digits/level=speed/size=1e4-8", BenchmarkName{
+		Base:          "Decode/text=digits/level=speed/size=1e4-8",
+		Function:      "Decode",
+		SubBenchmarks: []string{"text=digits", "level=speed", "size=1e4"},
+		Procs:         8,
+		HasProcs:      true,
+	}))
+	t.Run("case=dashnotnumber", verifyName("BenchmarkBob/name=bob-3n", BenchmarkName{
+		Base:          "Bob/name=bob-3n",
+		Function:      "Bob",
+		SubBenchmarks: []string{"name=bob-3n"},
+	}))
+	t.Run("case=justdash", verifyName("BenchmarkBob/name=bob-", BenchmarkName{
+		Base:          "Bob/name=bob-",
+		Function:      "Bob",
+		SubBenchmarks: []string{"name=bob-"},
+	}))
+}
+
+func TestBenchmarkResult_Procs(t *testing.T) {
+	verifyProcs := func(name string, expectedProcs int, expectedOk bool) func(t *testing.T) {
+		return func(t *testing.T) {
+			b := BenchmarkResult{Name: name}
+			procs, ok := b.Procs()
+			require.Equal(t, expectedProcs, procs)
+			require.Equal(t, expectedOk, ok)
+		}
+	}
+	t.Run("case=simple", verifyProcs("BenchmarkBob-8", 8, true))
+	t.Run("case=subbench", verifyProcs("BenchmarkBob/size=10-16", 16, true))
+	t.Run("case=none", verifyProcs("BenchmarkBob", 0, false))
+	t.Run("case=justdash", verifyProcs("BenchmarkBob-", 0, false))
+	t.Run("case=dashmixed", verifyProcs("BenchmarkBob-3n", 0, false))
+}
+
+func TestRun_Clone(t *testing.T) {
+	require.Nil(t, (*Run)(nil).Clone())
+	in := `commit: 7cd9055
+Unit ns/op better=lower
+BenchmarkA-8 100 100 ns/op
+BenchmarkA-8 100 101 ns/op
+BenchmarkA 100 102 ns/op
+ok  	github.com/cep21/a	1.2s
+`
+	run, err := Decoder{ProcsKey: "procs"}.Decode(strings.NewReader(in))
+	require.NoError(t, err)
+	cloned := run.Clone()
+	require.Equal(t, run.Results, cloned.Results)
+	require.Equal(t, run.Packages, cloned.Packages)
+	require.False(t, run.Results[0].Configuration == cloned.Results[0].Configuration)
+	require.True(t, cloned.Results[0].Configuration == cloned.Results[1].Configuration)
+	require.True(t, cloned.Results[0].Units == cloned.Results[2].Units)
+	require.False(t, run.Results[0].Units == cloned.Results[0].Units)
+	cloned.Results[0].Values[0].Value = 1
+	require.Equal(t, 100.0, run.Results[0].Values[0].Value)
+
+	var original, clone bytes.Buffer
+	require.NoError(t, (&Encoder{}).Encode(&original, run))
+	require.NoError(t, (&Encoder{}).Encode(&clone, run.Clone()))
+	require.Equal(t, original.String(), clone.String())
+	require.NotContains(t, clone.String(), "procs:")
+}
+
 func TestEncoder_Encode_symetric(t *testing.T) {
 	symetricEncode := func(s string) func(t *testing.T) {
 		return func(t *testing.T) {
@@ -245,4 +662,87 @@ BenchmarkDecode/text=digits/level=speed/size=1e4-8 100 154125 ns/op 64.88 MB/s 4
 commit: 7cd9056
 BenchmarkDecode/text=digits/level=speed/size=1e4-8 100 154125 ns/op 64.88 MB/s 40418 B/op 8 allocs/op
 `))
+	t.Run("case=units", symetricEncode(`Unit ns/op assume=exact better=lower
+BenchmarkBob 100 10 ns/op 3 misses/op
+Unit misses/op better=lower
+BenchmarkBob 100 10 ns/op 3 misses/op
+Unit ns/op assume=nothing
+BenchmarkBob 100 10 ns/op 3 misses/op
+Unit hits/op better=higher
+`))
+	t.Run("case=onlyunits", symetricEncode(`Unit ns/op better=lower
+`))
+	t.Run("case=removekeys", symetricEncode(`commit: 7cd9055
+cpu: Intel(R) Core(TM) i7-4980HQ CPU @ 2.80GHz
+BenchmarkBob 100 10 ns/op
+cpu:
+BenchmarkBob 100 10 ns/op
+commit:
+cpu: AMD EPYC 7B13
+BenchmarkBob 100 10 ns/op
+`))
+}
+
+func TestEncoder_Encode_align(t *testing.T) {
+	in := `commit: 7cd9055
+BenchmarkDecode/text=digits/level=speed/size=1e4-8 100 154125 ns/op 64.88 MB/s 40418 B/op 7 allocs/op
+BenchmarkDecode/text=digits/level=speed/size=1e6-8 1 13000839000 ns/op 76.92 MB/s 4001776 B/op 31 allocs/op
+BenchmarkEncode-8 20000 2.5 ns/op
+commit: 7cd9056
+Unit ns/op better=lower
+BenchmarkEncode-8 3 1 ns/op
+`
+	d := Decoder{}
+	run, err := d.Decode(strings.NewReader(in))
+	require.NoError(t, err)
+	e := Encoder{Align: true}
+	var buf bytes.Buffer
+	require.NoError(t, e.Encode(&buf, run))
+	require.Equal(t, "commit: 7cd9055\n"+
+		"BenchmarkDecode/text=digits/level=speed/size=1e4-8\t     100\t     154125 ns/op\t     64.88 MB/s\t     40418 B/op\t         7 allocs/op\n"+
+		"BenchmarkDecode/text=digits/level=speed/size=1e6-8\t       1\t13000839000 ns/op\t     76.92 MB/s\t   4001776 B/op\t        31 allocs/op\n"+
+		"BenchmarkEncode-8                                 \t   20000\t        2.5 ns/op\n"+
+		"commit: 7cd9056\n"+
+		"Unit ns/op better=lower\n"+
+		"BenchmarkEncode-8\t       3\t         1 ns/op\n", buf.String())
+
+	decoded, err := d.Decode(&buf)
+	require.NoError(t, err)
+	require.Equal(t, run, decoded)
+}
+
+func TestEncoder_Encode_removal(t *testing.T) {
+	config := &OrderedStringStringMap{
+		Contents: map[string]string{"commit": "7cd9055", "cpu": "amd"},
+		Order:    []string{"commit", "cpu"},
+	}
+	run := &Run{
+		Results: []BenchmarkResult{
+			{Name: "BenchmarkBob", Iterations: 1, Values: []ValueUnitPair{{Value: 10, Unit: "ns/op"}}, Configuration: config},
+			{Name: "BenchmarkBob", Iterations: 1, Values: []ValueUnitPair{{Value: 10, Unit: "ns/op"}}, Configuration: &OrderedStringStringMap{
+				Contents: map[string]string{"commit": "7cd9055"},
+				Order:    []string{"commit"},
+			}},
+			{Name: "BenchmarkBob", Iterations: 1, Values: []ValueUnitPair{{Value: 10, Unit: "ns/op"}}},
+		},
+	}
+	e := Encoder{}
+	var buf bytes.Buffer
+	require.NoError(t, e.Encode(&buf, run))
+	require.Equal(t, `commit: 7cd9055
+cpu: amd
+BenchmarkBob 1 10 ns/op
+cpu:
+BenchmarkBob 1 10 ns/op
+commit:
+BenchmarkBob 1 10 ns/op
+`, buf.String())
+
+	d := Decoder{}
+	decoded, err := d.Decode(&buf)
+	require.NoError(t, err)
+	require.Len(t, decoded.Results, 3)
+	require.Equal(t, config.Contents, decoded.Results[0].Configuration.Contents)
+	require.Equal(t, []string{"commit"}, decoded.Results[1].Configuration.Order)
+	require.Empty(t, decoded.Results[2].Configuration.Order)
 }
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/cep21/benchparse/analysis"
+	"github.com/cep21/benchparse/report"
+)
+
+// compareFormats are the values of the -format flag of compare
+var compareFormats = []string{formatText, formatMarkdown, formatHTML, formatJUnit}
+
+// Values of the -test flag of compare, named like the -delta-test flag of benchstat
+const (
+	testUTest = "utest"
+	testTTest = "ttest"
+)
+
+// runCompare compares an old and a new input.  Benchmarks only in one input are listed on stderr.  With a -threshold,
+// it fails if any unit is a significant regression by at least that many percent.
+func runCompare(c *cli, fs *flag.FlagSet, args []string) error {
+	var in inputFlags
+	in.register(fs)
+	format := fs.String("format", formatText, "format of the output: "+strings.Join(compareFormats, ", "))
+	test := fs.String("test", testUTest, "significance test: "+testUTest+" (Mann-Whitney U) or "+testTTest+" (Welch's t-test)")
+	alpha := fs.Float64("alpha", analysis.DefaultAlpha, "significance level")
+	threshold := fs.Float64("threshold", -1, "fail if any unit is a significant regression by at least this many percent.  Negative never fails.")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := in.validate(fs); err != nil {
+		return err
+	}
+	if !contains(compareFormats, *format) {
+		return usageErrorf(fs, "invalid -format %q", *format)
+	}
+	comparer := analysis.Comparer{Alpha: *alpha}
+	switch *test {
+	case testUTest:
+		comparer.Test = analysis.MannWhitneyU
+	case testTTest:
+		comparer.Test = analysis.WelchTTest
+	default:
+		return usageErrorf(fs, "invalid -test %q", *test)
+	}
+	if fs.NArg() != 2 {
+		return usageErrorf(fs, "compare needs an old and a new file")
+	}
+	old, err := c.readRun(fs.Args()[:1], in.from)
+	if err != nil {
+		return err
+	}
+	new, err := c.readRun(fs.Args()[1:], in.from)
+	if err != nil {
+		return err
+	}
+	comparison := comparer.Compare(old, new)
+	for _, g := range comparison.OldOnly {
+		fmt.Fprintf(c.stderr, "only in old: %s\n", g.Name())
+	}
+	for _, g := range comparison.NewOnly {
+		fmt.Fprintf(c.stderr, "only in new: %s\n", g.Name())
+	}
+	junit := &report.JUnitReporter{Threshold: *threshold}
+	switch *format {
+	case formatMarkdown:
+		err = (&report.MarkdownReporter{}).ReportComparison(c.stdout, comparison)
+	case formatHTML:
+		err = (&report.HTMLReporter{}).ReportComparison(c.stdout, comparison)
+	case formatJUnit:
+		if *threshold < 0 {
+			junit.Threshold = 0
+		}
+		err = junit.ReportComparison(c.stdout, comparison)
+	default:
+		err = (&report.TextReporter{}).ReportComparison(c.stdout, comparison)
+	}
+	if err != nil || *threshold < 0 {
+		return err
+	}
+	regressions := junit.Regressions(comparison)
+	for _, row := range regressions {
+		fmt.Fprintf(c.stderr, "regression: %s %s %s\n", row.Name, row.Unit, row.DeltaString())
+	}
+	if len(regressions) > 0 {
+		return errFailed
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"flag"
+)
+
+// runConvert decodes every input and writes it in another format
+func runConvert(c *cli, fs *flag.FlagSet, args []string) error {
+	var in inputFlags
+	var out outputFlags
+	in.register(fs)
+	out.register(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := in.validate(fs); err != nil {
+		return err
+	}
+	if err := out.validate(fs); err != nil {
+		return err
+	}
+	run, err := c.readRun(inputNames(fs.Args()), in.from)
+	if err != nil {
+		return err
+	}
+	return out.write(c.stdout, run)
+}
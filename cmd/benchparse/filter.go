@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/cep21/benchparse"
+	"github.com/cep21/benchparse/filter"
+)
+
+// runFilter writes the results of every input that match a filter expression.  Inputs are streamed, so only the
+// results that match are held in memory.  The unit metadata of every input is kept, but package sections are not.
+func runFilter(c *cli, fs *flag.FlagSet, args []string) error {
+	var in inputFlags
+	var out outputFlags
+	in.register(fs)
+	out.register(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := in.validate(fs); err != nil {
+		return err
+	}
+	if err := out.validate(fs); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return usageErrorf(fs, "missing filter expression")
+	}
+	f, err := filter.Parse(fs.Arg(0))
+	if err != nil {
+		return usageErrorf(fs, "invalid filter expression %q: %s", fs.Arg(0), err)
+	}
+	run := &benchparse.Run{}
+	run.Units, err = c.streamResults(inputNames(fs.Args()[1:]), in.from, f.Stream(func(result benchparse.BenchmarkResult) {
+		run.Results = append(run.Results, result)
+	}))
+	if err != nil {
+		return err
+	}
+	return out.write(c.stdout, run)
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cep21/benchparse"
+	"github.com/cep21/benchparse/tabular"
+)
+
+// Input formats
+const (
+	formatAuto      = "auto"
+	formatBench     = "bench"
+	formatTest2JSON = "test2json"
+	formatJSON      = "json"
+	formatCSV       = "csv"
+	formatTSV       = "tsv"
+)
+
+// inputFormats are the values of the -from flag
+var inputFormats = []string{formatAuto, formatBench, formatTest2JSON, formatJSON, formatCSV, formatTSV}
+
+// stdinName is the file name of standard input
+const stdinName = "-"
+
+// sniffSize is how much of an input is looked at to detect its format
+const sniffSize = 4096
+
+// inputFlags are the flags of commands that read results
+type inputFlags struct {
+	from string
+}
+
+func (i *inputFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&i.from, "from", formatAuto, "format of the input: "+strings.Join(inputFormats, ", "))
+}
+
+// validate returns errUsage for a -from flag that is not an input format
+func (i *inputFlags) validate(fs *flag.FlagSet) error {
+	if !contains(inputFormats, i.from) {
+		return usageErrorf(fs, "invalid -from %q", i.from)
+	}
+	return nil
+}
+
+// input is a single opened input
+type input struct {
+	name   string
+	format string
+	reader *bufio.Reader
+	closer io.Closer
+}
+
+// open opens the file called name, or standard input for stdinName, and detects its format
+func (c *cli) open(name string, from string) (*input, error) {
+	ret := &input{name: name, format: from}
+	if name == stdinName {
+		ret.name = "<stdin>"
+		ret.reader = bufio.NewReaderSize(c.stdin, sniffSize)
+	} else {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		ret.reader = bufio.NewReaderSize(f, sniffSize)
+		ret.closer = f
+	}
+	if ret.format == formatAuto {
+		ret.format = detectFormat(name, ret.reader)
+	}
+	return ret, nil
+}
+
+func (i *input) Close() error {
+	if i.closer == nil {
+		return nil
+	}
+	return i.closer.Close()
+}
+
+// detectFormat returns the format of the input called name from its extension, or else from its first bytes.  JSON
+// input is a Run unless its first line is a "go test -json" event.  Tables start with a name column.
+func detectFormat(name string, r *bufio.Reader) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return formatCSV
+	case ".tsv":
+		return formatTSV
+	}
+	start, _ := r.Peek(sniffSize)
+	start = bytes.TrimLeft(start, " \t\r\n")
+	firstLine := start
+	if newline := bytes.IndexByte(start, '\n'); newline != -1 {
+		firstLine = start[:newline]
+	}
+	switch {
+	case bytes.HasPrefix(start, []byte("{")) && bytes.Contains(firstLine, []byte(`"Action"`)):
+		return formatTest2JSON
+	case bytes.HasPrefix(start, []byte("{")):
+		return formatJSON
+	case bytes.HasPrefix(firstLine, []byte(tabular.NameHeader+",")):
+		return formatCSV
+	case bytes.HasPrefix(firstLine, []byte(tabular.NameHeader+"\t")):
+		return formatTSV
+	}
+	return formatBench
+}
+
+// inputNames returns the files to read, which is standard input if there are none
+func inputNames(args []string) []string {
+	if len(args) == 0 {
+		return []string{stdinName}
+	}
+	return args
+}
+
+// readRun decodes every file of names and merges them into a single Run
+func (c *cli) readRun(names []string, from string) (*benchparse.Run, error) {
+	ret := &benchparse.Run{}
+	for _, name := range names {
+		run, err := c.readFile(name, from)
+		if err != nil {
+			return nil, err
+		}
+		mergeRun(ret, run)
+	}
+	return ret, nil
+}
+
+// readFile decodes a single file
+func (c *cli) readFile(name string, from string) (*benchparse.Run, error) {
+	in, err := c.open(name, from)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = in.Close()
+	}()
+	run, err := in.decode(benchparse.Decoder{})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", in.name, err)
+	}
+	return run, nil
+}
+
+// decode decodes the whole input.  d configures how the benchmark format, and the output inside "go test -json"
+// events, are decoded.
+func (i *input) decode(d benchparse.Decoder) (*benchparse.Run, error) {
+	switch i.format {
+	case formatTest2JSON:
+		return benchparse.Test2JSONDecoder{Decoder: d}.Decode(i.reader)
+	case formatJSON:
+		var run benchparse.Run
+		if err := json.NewDecoder(i.reader).Decode(&run); err != nil {
+			return nil, err
+		}
+		return &run, nil
+	case formatCSV:
+		return tabular.Decoder{}.Decode(i.reader)
+	case formatTSV:
+		return tabular.Decoder{Comma: '\t'}.Decode(i.reader)
+	default:
+		return d.Decode(i.reader)
+	}
+}
+
+// streamResults calls onResult with each result of every file of names, and returns the unit metadata of all of them.
+// Input in the benchmark format, or from "go test -json", is streamed without holding the whole input in memory.
+func (c *cli) streamResults(names []string, from string, onResult func(result benchparse.BenchmarkResult)) (*benchparse.UnitMetadata, error) {
+	var units *benchparse.UnitMetadata
+	for _, name := range names {
+		fileUnits, err := c.streamFile(name, from, onResult)
+		if err != nil {
+			return nil, err
+		}
+		units = mergeUnits(units, fileUnits)
+	}
+	return units, nil
+}
+
+func (c *cli) streamFile(name string, from string, onResult func(result benchparse.BenchmarkResult)) (*benchparse.UnitMetadata, error) {
+	in, err := c.open(name, from)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = in.Close()
+	}()
+	var units *benchparse.UnitMetadata
+	d := benchparse.Decoder{
+		OnUnits: func(fileUnits *benchparse.UnitMetadata) {
+			units = mergeUnits(units, fileUnits)
+		},
+	}
+	switch in.format {
+	case formatBench:
+		err = d.Stream(context.Background(), in.reader, onResult)
+	case formatTest2JSON:
+		err = benchparse.Test2JSONDecoder{Decoder: d}.Stream(context.Background(), in.reader, onResult)
+	default:
+		var run *benchparse.Run
+		run, err = in.decode(benchparse.Decoder{})
+		if err == nil {
+			for _, r := range run.Results {
+				onResult(r)
+			}
+			units = run.Units
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", in.name, err)
+	}
+	return units, nil
+}
+
+// mergeRun appends the results and packages of run to into, and merges the unit metadata of run into that of into.
+func mergeRun(into *benchparse.Run, run *benchparse.Run) {
+	offset := len(into.Results)
+	into.Results = append(into.Results, run.Results...)
+	for _, p := range run.Packages {
+		p.ResultsStart += offset
+		p.ResultsEnd += offset
+		into.Packages = append(into.Packages, p)
+	}
+	into.Units = mergeUnits(into.Units, run.Units)
+}
+
+// mergeUnits returns the metadata of both into and units.  Like later Unit lines of a single input, metadata of units
+// replaces the same key of the same unit.  Neither is modified, since decoded results share them.
+func mergeUnits(into *benchparse.UnitMetadata, units *benchparse.UnitMetadata) *benchparse.UnitMetadata {
+	if into == nil || into == units {
+		return units
+	}
+	ret := into.Clone()
+	ret.Merge(units)
+	return ret
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+/*
+Command benchparse converts, filters, summarizes, compares, and validates Go benchmark results.
+
+Usage:
+
+	benchparse <command> [flags] [file ...]
+
+The commands are:
+
+	convert   convert results between the benchmark format, JSON, CSV, TSV, Markdown, HTML, and more
+	filter    keep only the results that match a filter expression
+	stat      summarize repeated results, optionally pivoted into a table
+	compare   compare an old and a new set of results
+	validate  report every line that is not valid benchmark format
+
+Commands read the files given as arguments, or standard input if there are none or a file is "-".  The format of the
+input is detected from its contents unless the -from flag sets it.  Run "benchparse <command> -h" for the flags of a
+command.
+
+benchparse exits with 1 if a command fails, for example because of a file it could not decode, an invalid line found
+by validate, or a regression found by compare with -threshold.  It exits with 2 for invalid flags or arguments.
+*/
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// cli is where a command reads input and writes output
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// command is a single subcommand of benchparse
+type command struct {
+	name string
+	// usage is the arguments of the command, after its name
+	usage string
+	// description is a single sentence that describes the command
+	description string
+	run         func(c *cli, fs *flag.FlagSet, args []string) error
+}
+
+// commands are every subcommand, in the order usage lists them
+var commands = []command{
+	{name: "convert", usage: "[flags] [file ...]", description: "Convert results to another format.", run: runConvert},
+	{name: "filter", usage: "[flags] expression [file ...]", description: "Keep only the results that match a filter expression.  See the filter package for the syntax.", run: runFilter},
+	{name: "stat", usage: "[flags] [file ...]", description: "Summarize repeated results, or pivot them into a table with -rows or -cols.", run: runStat},
+	{name: "compare", usage: "[flags] old new", description: "Compare the results of an old and a new file.", run: runCompare},
+	{name: "validate", usage: "[flags] [file ...]", description: "Report every line that is not valid benchmark format.", run: runValidate},
+}
+
+var (
+	// errFailed is returned by commands that already reported why they failed, like validate finding invalid lines
+	errFailed = errors.New("failed")
+	// errUsage is returned by commands for invalid flags or arguments, after the flag set has printed why
+	errUsage = errors.New("invalid usage")
+)
+
+// Exit codes of benchparse
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+// run runs the command of args and returns the exit code
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return exitUsage
+	}
+	if args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+		printUsage(stdout)
+		return exitOK
+	}
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+		fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+		fs.SetOutput(stderr)
+		cmd := cmd
+		fs.Usage = func() {
+			fmt.Fprintf(fs.Output(), "usage: benchparse %s %s\n\n%s\n\n", cmd.name, cmd.usage, cmd.description)
+			fs.PrintDefaults()
+		}
+		err := cmd.run(&cli{stdin: stdin, stdout: stdout, stderr: stderr}, fs, args[1:])
+		switch err {
+		case nil:
+			return exitOK
+		case flag.ErrHelp:
+			return exitOK
+		case errUsage:
+			return exitUsage
+		case errFailed:
+			return exitFailure
+		default:
+			fmt.Fprintf(stderr, "benchparse %s: %s\n", cmd.name, err)
+			return exitFailure
+		}
+	}
+	fmt.Fprintf(stderr, "benchparse: unknown command %q\n", args[0])
+	printUsage(stderr)
+	return exitUsage
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "usage: benchparse <command> [flags] [file ...]\n\ncommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-9s %s\n", cmd.name, cmd.description)
+	}
+}
+
+// parseFlags parses args into fs.  Invalid flags are returned as errUsage, since fs already printed why.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return err
+		}
+		return errUsage
+	}
+	return nil
+}
+
+// usageErrorf prints a usage error and the usage of fs, and returns errUsage
+func usageErrorf(fs *flag.FlagSet, format string, args ...interface{}) error {
+	fmt.Fprintf(fs.Output(), format+"\n", args...)
+	fs.Usage()
+	return errUsage
+}
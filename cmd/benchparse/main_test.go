@@ -0,0 +1,296 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cep21/benchparse"
+	"github.com/stretchr/testify/require"
+)
+
+const oldExample = `commit: 7cd9055
+BenchmarkDecode/size=1e4-8 100 154125 ns/op
+BenchmarkDecode/size=1e4-8 100 155125 ns/op
+BenchmarkDecode/size=1e4-8 100 153125 ns/op
+BenchmarkDecode/size=1e4-8 100 154525 ns/op
+BenchmarkDecode/size=1e4-8 100 154225 ns/op
+BenchmarkEncode/fast-8 2000 1.5 ns/op
+`
+
+const newExample = `commit: 7cd9056
+BenchmarkDecode/size=1e4-8 100 164125 ns/op
+BenchmarkDecode/size=1e4-8 100 165125 ns/op
+BenchmarkDecode/size=1e4-8 100 163125 ns/op
+BenchmarkDecode/size=1e4-8 100 164525 ns/op
+BenchmarkDecode/size=1e4-8 100 164225 ns/op
+BenchmarkEncode/fast-8 2000 1.5 ns/op
+`
+
+// result is the outcome of running benchparse
+type result struct {
+	code   int
+	stdout string
+	stderr string
+}
+
+func runWith(stdin string, args ...string) result {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return result{code: code, stdout: stdout.String(), stderr: stderr.String()}
+}
+
+// writeFile writes contents to a file called name inside dir and returns its path
+func writeFile(t *testing.T, dir string, name string, contents string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0600))
+	return path
+}
+
+func tempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "benchparse")
+	require.NoError(t, err)
+	return dir, func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}
+}
+
+func TestRun_usage(t *testing.T) {
+	r := runWith("")
+	require.Equal(t, exitUsage, r.code)
+	require.Contains(t, r.stderr, "usage: benchparse <command>")
+
+	r = runWith("", "help")
+	require.Equal(t, exitOK, r.code)
+	require.Contains(t, r.stdout, "validate")
+
+	r = runWith("", "nope")
+	require.Equal(t, exitUsage, r.code)
+	require.Contains(t, r.stderr, `unknown command "nope"`)
+
+	r = runWith("", "convert", "-h")
+	require.Equal(t, exitOK, r.code)
+	require.Contains(t, r.stderr, "usage: benchparse convert")
+
+	r = runWith("", "convert", "-nope")
+	require.Equal(t, exitUsage, r.code)
+
+	r = runWith("", "convert", "-to", "xml")
+	require.Equal(t, exitUsage, r.code)
+	require.Contains(t, r.stderr, `invalid -to "xml"`)
+
+	r = runWith("", "convert", "-from", "xml")
+	require.Equal(t, exitUsage, r.code)
+	require.Contains(t, r.stderr, `invalid -from "xml"`)
+}
+
+func TestRun_convert(t *testing.T) {
+	r := runWith(oldExample, "convert")
+	require.Equal(t, exitOK, r.code)
+	require.Equal(t, oldExample, r.stdout)
+
+	r = runWith("BenchmarkBob-8 1 10 ns/op\n", "convert", "-to", "csv")
+	require.Equal(t, exitOK, r.code)
+	require.Equal(t, "name,iterations,ns/op\nBenchmarkBob-8,1,10\n", r.stdout)
+
+	r = runWith("BenchmarkBob-8 1 10 ns/op\n", "convert", "-to", "markdown")
+	require.Equal(t, exitOK, r.code)
+	require.Equal(t, "### Bob\n\n| ns/op |\n| --: |\n| 10.00 ns/op |\n", r.stdout)
+
+	// Every format reads back what convert writes
+	for _, to := range []string{"json", "csv", "tsv"} {
+		args := []string{"convert", "-to", to}
+		if to != "json" {
+			args = append(args, "-annotate")
+		}
+		converted := runWith(oldExample, args...)
+		require.Equal(t, exitOK, converted.code, to)
+		back := runWith(converted.stdout, "convert")
+		require.Equal(t, exitOK, back.code, to)
+		require.Equal(t, oldExample, back.stdout, to)
+	}
+
+	r = runWith(`{"Action":"output","Package":"github.com/cep21/a","Output":"BenchmarkBob-8 \t 1\t 10 ns/op\n"}
+`, "convert")
+	require.Equal(t, exitOK, r.code)
+	require.Equal(t, "pkg: github.com/cep21/a\nBenchmarkBob-8 1 10 ns/op\n", r.stdout)
+
+	r = runWith("{", "convert", "-from", "json")
+	require.Equal(t, exitFailure, r.code)
+	require.Contains(t, r.stderr, "benchparse convert: <stdin>: unexpected EOF")
+}
+
+func TestRun_convertFiles(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	oldFile := writeFile(t, dir, "old.txt", oldExample)
+	csvFile := writeFile(t, dir, "new.csv", "name,unit:ns/op\nBenchmarkJack,3\n")
+	r := runWith("", "convert", oldFile, csvFile)
+	require.Equal(t, exitOK, r.code)
+	require.Equal(t, oldExample+"commit:\nBenchmarkJack 1 3 ns/op\n", r.stdout)
+
+	unitsA := writeFile(t, dir, "a.txt", "Unit ns/op better=lower\nBenchmarkA 1 10 ns/op\nUnit B/op assume=exact\n")
+	unitsB := writeFile(t, dir, "b.txt", "Unit ns/op assume=nothing\nBenchmarkB 1 10 ns/op\nUnit MB/s better=higher\n")
+	r = runWith("", "convert", "-to", "json", unitsA, unitsB)
+	require.Equal(t, exitOK, r.code)
+	var merged benchparse.Run
+	require.NoError(t, json.Unmarshal([]byte(r.stdout), &merged))
+	require.Equal(t, []string{"ns/op", "B/op", "MB/s"}, merged.Units.Order)
+	better, _ := merged.Units.Lookup("ns/op", "better")
+	require.Equal(t, "lower", better)
+	assume, _ := merged.Units.Lookup("ns/op", "assume")
+	require.Equal(t, "nothing", assume)
+
+	r = runWith("", "convert", filepath.Join(dir, "missing.txt"))
+	require.Equal(t, exitFailure, r.code)
+	require.Contains(t, r.stderr, "missing.txt")
+}
+
+func TestRun_filter(t *testing.T) {
+	r := runWith(oldExample, "filter", ".name:Encode")
+	require.Equal(t, exitOK, r.code)
+	require.Equal(t, "commit: 7cd9055\nBenchmarkEncode/fast-8 2000 1.5 ns/op\n", r.stdout)
+
+	r = runWith(oldExample, "filter", "-to", "csv", "ns/op>154300")
+	require.Equal(t, exitOK, r.code)
+	require.Equal(t, `name,iterations,commit,size,ns/op
+BenchmarkDecode/size=1e4-8,100,7cd9055,1e4,155125
+BenchmarkDecode/size=1e4-8,100,7cd9055,1e4,154525
+`, r.stdout)
+
+	units := "Unit ns/op better=lower\nBenchmarkDecode 1 10 ns/op\nBenchmarkEncode 1 10 ns/op\nUnit B/op assume=exact\n"
+	r = runWith(units, "filter", ".name:Decode")
+	require.Equal(t, exitOK, r.code)
+	require.Equal(t, "Unit ns/op better=lower\nBenchmarkDecode 1 10 ns/op\nUnit B/op assume=exact\n", r.stdout)
+
+	r = runWith(units, "filter", ".name:Nothing")
+	require.Equal(t, exitOK, r.code)
+	require.Equal(t, "Unit ns/op better=lower\nUnit B/op assume=exact\n", r.stdout)
+
+	r = runWith(`{"Action":"output","Package":"github.com/cep21/a","Output":"Unit ns/op better=lower\n"}
+{"Action":"output","Package":"github.com/cep21/a","Output":"BenchmarkBob-8 \t 1\t 10 ns/op\n"}
+{"Action":"output","Package":"github.com/cep21/b","Output":"Unit B/op assume=exact\n"}
+`, "filter", ".name:Nothing")
+	require.Equal(t, exitOK, r.code)
+	require.Equal(t, "Unit ns/op better=lower\nUnit B/op assume=exact\n", r.stdout)
+
+	r = runWith(oldExample, "filter")
+	require.Equal(t, exitUsage, r.code)
+	require.Contains(t, r.stderr, "missing filter expression")
+
+	r = runWith(oldExample, "filter", "goos:")
+	require.Equal(t, exitUsage, r.code)
+	require.Contains(t, r.stderr, `invalid filter expression "goos:"`)
+}
+
+func TestRun_stat(t *testing.T) {
+	r := runWith(oldExample, "stat")
+	require.Equal(t, exitOK, r.code)
+	require.Equal(t, `commit: 7cd9055
+
+Decode
+size=1e4
+           ns/op
+154.2 µs/op ± 1%
+
+Encode
+name        ns/op
+fast  1.500 ns/op
+`, r.stdout)
+
+	r = runWith(oldExample+newExample, "stat", "-format", "csv", "-rows", "commit", "-cols", "size", "-aggregate", "max")
+	require.Equal(t, exitOK, r.code)
+	require.Equal(t, "commit,size=1e4,size=\n7cd9055,155125,1.5\n7cd9056,165125,1.5\n", r.stdout)
+
+	r = runWith(oldExample, "stat", "-format", "csv")
+	require.Equal(t, exitUsage, r.code)
+	require.Contains(t, r.stderr, "-format csv needs -rows or -cols")
+
+	r = runWith(oldExample, "stat", "-aggregate", "sum", "-rows", "commit")
+	require.Equal(t, exitUsage, r.code)
+}
+
+func TestRun_compare(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	oldFile := writeFile(t, dir, "old.txt", oldExample)
+	newFile := writeFile(t, dir, "new.txt", newExample)
+
+	r := runWith("", "compare", oldFile, newFile)
+	require.Equal(t, exitOK, r.code)
+	require.Contains(t, r.stdout, "154.2 µs/op ± 1%  164.2 µs/op ± 1%  +6.48%  p=0.008 n=5+5\n")
+
+	r = runWith("", "compare", "-threshold", "5", oldFile, newFile)
+	require.Equal(t, exitFailure, r.code)
+	require.Equal(t, "regression: Decode/size=1e4 ns/op +6.48%\n", r.stderr)
+
+	r = runWith("", "compare", "-threshold", "10", oldFile, newFile)
+	require.Equal(t, exitOK, r.code)
+
+	r = runWith("", "compare", "-threshold", "0", newFile, oldFile)
+	require.Equal(t, exitOK, r.code)
+
+	onlyOldFile := writeFile(t, dir, "only.txt", "cpu: x\nBenchmarkA 1 10 ns/op\nBenchmarkA 1 10 ns/op\nBenchmarkB 1 10 ns/op\n")
+	onlyNewFile := writeFile(t, dir, "only-new.txt", "BenchmarkA 1 20 ns/op\nBenchmarkA 1 20 ns/op\nBenchmarkC 1 10 ns/op\n")
+	r = runWith("", "compare", "-threshold", "5", "-test", "ttest", onlyOldFile, onlyNewFile)
+	require.Equal(t, exitFailure, r.code)
+	require.Contains(t, r.stdout, "+100.00%")
+	require.Equal(t, "only in old: B\nonly in new: C\nregression: A ns/op +100.00%\n", r.stderr)
+
+	r = runWith("", "compare", "-format", "junit", oldFile, newFile)
+	require.Equal(t, exitOK, r.code)
+	require.Contains(t, r.stdout, `<testsuite name="benchmarks" tests="2" failures="1">`)
+
+	r = runWith("", "compare", "-test", "ttest", "-format", "markdown", oldFile, newFile)
+	require.Equal(t, exitOK, r.code)
+	require.Contains(t, r.stdout, ":red_circle:")
+
+	r = runWith("", "compare", oldFile)
+	require.Equal(t, exitUsage, r.code)
+	require.Contains(t, r.stderr, "compare needs an old and a new file")
+
+	r = runWith("", "compare", "-test", "ztest", oldFile, newFile)
+	require.Equal(t, exitUsage, r.code)
+}
+
+func TestRun_validate(t *testing.T) {
+	r := runWith(oldExample, "validate")
+	require.Equal(t, exitOK, r.code)
+	require.Empty(t, r.stdout)
+
+	r = runWith("BenchmarkBob 1 x ns/op\nhello\nBenchmarkJack 1 1 ns/op\n", "validate")
+	require.Equal(t, exitFailure, r.code)
+	require.Equal(t, `<stdin>:1: invalid benchmark result line "BenchmarkBob 1 x ns/op": strconv.ParseFloat: parsing "x": invalid syntax
+<stdin>:2: invalid configuration line "hello": invalid keyvalue: key has no colon
+`, r.stdout)
+
+	r = runWith("name,unit:ns/op\nBenchmarkBob,x\n", "validate")
+	require.Equal(t, exitFailure, r.code)
+	require.Equal(t, "<stdin>: row 2: column \"unit:ns/op\": strconv.ParseFloat: parsing \"x\": invalid syntax\n", r.stdout)
+}
+
+func TestDetectFormat(t *testing.T) {
+	cases := []struct {
+		name     string
+		contents string
+		expected string
+	}{
+		{name: "old.txt", contents: oldExample, expected: formatBench},
+		{name: "-", contents: "", expected: formatBench},
+		{name: "results.CSV", contents: oldExample, expected: formatCSV},
+		{name: "results.tsv", contents: "", expected: formatTSV},
+		{name: "-", contents: "name,unit:ns/op\n", expected: formatCSV},
+		{name: "-", contents: "name\tunit:ns/op\n", expected: formatTSV},
+		{name: "-", contents: "\n  {\n\"Results\": []}", expected: formatJSON},
+		{name: "-", contents: `{"Action":"output","Output":"PASS\n"}` + "\n", expected: formatTest2JSON},
+	}
+	for _, tc := range cases {
+		r := bufio.NewReader(strings.NewReader(tc.contents))
+		require.Equal(t, tc.expected, detectFormat(tc.name, r), "%s: %q", tc.name, tc.contents)
+	}
+}
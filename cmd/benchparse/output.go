@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"strings"
+
+	"github.com/cep21/benchparse"
+	"github.com/cep21/benchparse/export"
+	"github.com/cep21/benchparse/report"
+	"github.com/cep21/benchparse/tabular"
+)
+
+// Output formats, in addition to the input formats bench, json, csv, and tsv
+const (
+	formatText       = "text"
+	formatMarkdown   = "markdown"
+	formatHTML       = "html"
+	formatJUnit      = "junit"
+	formatPrometheus = "prometheus"
+	formatInflux     = "influx"
+)
+
+// outputFormats are the values of the -to flag
+var outputFormats = []string{formatBench, formatJSON, formatCSV, formatTSV, formatText, formatMarkdown, formatHTML, formatPrometheus, formatInflux}
+
+// outputFlags are the flags of commands that write results
+type outputFlags struct {
+	to       string
+	align    bool
+	annotate bool
+}
+
+func (o *outputFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.to, "to", formatBench, "format of the output: "+strings.Join(outputFormats, ", "))
+	fs.BoolVar(&o.align, "align", false, "column align results of the bench format")
+	fs.BoolVar(&o.annotate, "annotate", false, "annotate the header of csv and tsv so it can be read back")
+}
+
+// validate returns errUsage for a -to flag that is not an output format
+func (o *outputFlags) validate(fs *flag.FlagSet) error {
+	if !contains(outputFormats, o.to) {
+		return usageErrorf(fs, "invalid -to %q", o.to)
+	}
+	return nil
+}
+
+// write writes run to w in the format of the -to flag
+func (o *outputFlags) write(w io.Writer, run *benchparse.Run) error {
+	switch o.to {
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(run)
+	case formatCSV:
+		return (&tabular.Encoder{Annotate: o.annotate}).Encode(w, run)
+	case formatTSV:
+		return (&tabular.Encoder{Comma: '\t', Annotate: o.annotate}).Encode(w, run)
+	case formatText:
+		return (&report.TextReporter{}).ReportRun(w, run)
+	case formatMarkdown:
+		return (&report.MarkdownReporter{}).ReportRun(w, run)
+	case formatHTML:
+		return (&report.HTMLReporter{}).ReportRun(w, run)
+	case formatPrometheus:
+		return (&export.PrometheusEncoder{}).Encode(w, run)
+	case formatInflux:
+		return (&export.InfluxEncoder{}).Encode(w, run)
+	default:
+		return (&benchparse.Encoder{Align: o.align}).Encode(w, run)
+	}
+}
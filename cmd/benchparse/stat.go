@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/cep21/benchparse/analysis"
+	"github.com/cep21/benchparse/report"
+	"github.com/cep21/benchparse/tabular"
+)
+
+// statFormats are the values of the -format flag of stat.  csv and tsv are only for pivot tables.
+var statFormats = []string{formatText, formatMarkdown, formatHTML, formatCSV, formatTSV}
+
+// aggregations are the values of the -aggregate flag of stat
+var aggregations = []analysis.Aggregation{analysis.AggregationMedian, analysis.AggregationMean, analysis.AggregationMin, analysis.AggregationMax}
+
+// runStat summarizes the results of every input, or pivots them into a table
+func runStat(c *cli, fs *flag.FlagSet, args []string) error {
+	var in inputFlags
+	in.register(fs)
+	format := fs.String("format", formatText, "format of the output: "+strings.Join(statFormats, ", "))
+	rows := fs.String("rows", "", "comma separated keys that tell apart the rows of a pivot table")
+	cols := fs.String("cols", "", "comma separated keys that tell apart the columns of a pivot table")
+	unit := fs.String("unit", "", "unit of the cells of a pivot table.  Defaults to the first unit.")
+	aggregationNames := make([]string, 0, len(aggregations))
+	for _, a := range aggregations {
+		aggregationNames = append(aggregationNames, a.String())
+	}
+	aggregate := fs.String("aggregate", analysis.AggregationMedian.String(), "statistic of the cells of a pivot table: "+strings.Join(aggregationNames, ", "))
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := in.validate(fs); err != nil {
+		return err
+	}
+	if !contains(statFormats, *format) {
+		return usageErrorf(fs, "invalid -format %q", *format)
+	}
+	pivot := analysis.Pivot{Rows: splitKeys(*rows), Columns: splitKeys(*cols), Unit: *unit}
+	isPivot := len(pivot.Rows) > 0 || len(pivot.Columns) > 0
+	if !isPivot && (*format == formatCSV || *format == formatTSV) {
+		return usageErrorf(fs, "-format %s needs -rows or -cols", *format)
+	}
+	if !contains(aggregationNames, *aggregate) {
+		return usageErrorf(fs, "invalid -aggregate %q", *aggregate)
+	}
+	for _, a := range aggregations {
+		if a.String() == *aggregate {
+			pivot.Aggregation = a
+		}
+	}
+	run, err := c.readRun(inputNames(fs.Args()), in.from)
+	if err != nil {
+		return err
+	}
+	if !isPivot {
+		switch *format {
+		case formatMarkdown:
+			return (&report.MarkdownReporter{}).ReportRun(c.stdout, run)
+		case formatHTML:
+			return (&report.HTMLReporter{}).ReportRun(c.stdout, run)
+		default:
+			return (&report.TextReporter{}).ReportRun(c.stdout, run)
+		}
+	}
+	table := pivot.Table(run)
+	switch *format {
+	case formatMarkdown:
+		return (&report.MarkdownReporter{}).ReportTable(c.stdout, table)
+	case formatHTML:
+		return (&report.HTMLReporter{}).ReportTable(c.stdout, table)
+	case formatCSV:
+		return (&tabular.Encoder{}).EncodeTable(c.stdout, table)
+	case formatTSV:
+		return (&tabular.Encoder{Comma: '\t'}).EncodeTable(c.stdout, table)
+	default:
+		return (&report.TextReporter{}).ReportTable(c.stdout, table)
+	}
+}
+
+// splitKeys splits a comma separated list of keys
+func splitKeys(keys string) []string {
+	if keys == "" {
+		return nil
+	}
+	return strings.Split(keys, ",")
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/cep21/benchparse"
+)
+
+// runValidate reports every line of every input that is not valid benchmark format, and fails if there are any.
+// Inputs that are not in the benchmark format fail if they cannot be decoded.
+func runValidate(c *cli, fs *flag.FlagSet, args []string) error {
+	var in inputFlags
+	in.register(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := in.validate(fs); err != nil {
+		return err
+	}
+	failed := false
+	for _, name := range inputNames(fs.Args()) {
+		valid, err := c.validateFile(name, in.from)
+		if err != nil {
+			return err
+		}
+		failed = failed || !valid
+	}
+	if failed {
+		return errFailed
+	}
+	return nil
+}
+
+// validateFile writes each invalid line of the file called name to stdout, and returns false if there were any
+func (c *cli) validateFile(name string, from string) (bool, error) {
+	in, err := c.open(name, from)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = in.Close()
+	}()
+	valid := true
+	d := benchparse.Decoder{
+		OnLineError: func(err *benchparse.LineError) {
+			valid = false
+			fmt.Fprintf(c.stdout, "%s:%d: invalid %s line %q: %s\n", in.name, err.Line, err.Kind, err.Text, err.Err)
+		},
+	}
+	if _, err := in.decode(d); err != nil {
+		fmt.Fprintf(c.stdout, "%s: %s\n", in.name, err)
+		return false, nil
+	}
+	return valid, nil
+}
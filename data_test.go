@@ -51,3 +51,40 @@ BenchmarkEncode/text=digits/level=best/size=1e6-8    	       1	 137962041 ns/op
   akey: bob
 BenchmarkEncode/text=normal/level=best/size=1e6-8    	       1	 137962041 ns/op	   7.25 MB/s
 `
+
+const test2JSONExample = `{"Time":"2019-09-20T10:14:05.1Z","Action":"output","Package":"github.com/cep21/a","Output":"goos: linux\n"}
+{"Time":"2019-09-20T10:14:05.1Z","Action":"output","Package":"github.com/cep21/a","Output":"goarch: amd64\n"}
+{"Time":"2019-09-20T10:14:05.1Z","Action":"output","Package":"github.com/cep21/b","Output":"goos: linux\n"}
+{"Time":"2019-09-20T10:14:05.1Z","Action":"output","Package":"github.com/cep21/a","Output":"pkg: github.com/cep21/a\n"}
+{"Time":"2019-09-20T10:14:05.1Z","Action":"run","Package":"github.com/cep21/a","Test":"BenchmarkFoo"}
+{"Time":"2019-09-20T10:14:05.1Z","Action":"output","Package":"github.com/cep21/a","Test":"BenchmarkFoo","Output":"BenchmarkFoo-8   \t"}
+{"Time":"2019-09-20T10:14:05.1Z","Action":"output","Package":"github.com/cep21/b","Test":"BenchmarkBar","Output":"BenchmarkBar/size=1-8   \t     100\t         2.020 ns/op\t       0 B/op\t       0 allocs/op\n"}
+{"Time":"2019-09-20T10:14:05.1Z","Action":"output","Package":"github.com/cep21/a","Test":"BenchmarkFoo","Output":"     100\t         3.240 ns/op\n"}
+{"Time":"2019-09-20T10:14:05.1Z","Action":"output","Package":"github.com/cep21/a","Output":"PASS\n"}
+{"Time":"2019-09-20T10:14:05.1Z","Action":"output","Package":"github.com/cep21/a","Output":"ok  \tgithub.com/cep21/a\t0.006s\n"}
+{"Time":"2019-09-20T10:14:05.1Z","Action":"pass","Package":"github.com/cep21/a","Elapsed":0.006}
+{"Time":"2019-09-20T10:14:05.1Z","Action":"output","Package":"github.com/cep21/b","Test":"BenchmarkBar","Output":"BenchmarkBar/size=2-8   \t     100\t         4.020 ns/op"}
+`
+
+const goTestExample = `goos: linux
+goarch: amd64
+pkg: github.com/cep21/a
+BenchmarkFoo-8   	     100	         3.24 ns/op
+BenchmarkBar-8   	     100	         2.02 ns/op
+PASS
+ok  	github.com/cep21/a	1.302s
+goos: linux
+goarch: amd64
+pkg: github.com/cep21/b
+BenchmarkBaz-8   	     100	         3.24 ns/op
+--- FAIL: BenchmarkBuzz-8
+    b_test.go:12: oh no
+FAIL
+exit status 1
+FAIL	github.com/cep21/b	0.510s
+ok  	github.com/cep21/c	(cached)
+goos: linux
+goarch: amd64
+pkg: github.com/cep21/d
+BenchmarkQux-8   	     100	         3.24 ns/op
+`
@@ -8,13 +8,42 @@ import (
 	"io"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 )
 
 // Decoder helps configure how to decode benchmark results.
 type Decoder struct {
+	// ProcsKey, if not empty, is a configuration key the Decoder sets on each BenchmarkResult to the GOMAXPROCS value
+	// parsed from the "-N" suffix of the benchmark name.  Results without a "-N" suffix will not have the key.  This
+	// lets AllKeyValuePairs tell apart benchmarks run with different -cpu values.  A common value is "procs".  The key
+	// is synthetic: if the input sets ProcsKey itself, the input's value is kept, and Encoder does not write the key
+	// as a configuration line.
+	ProcsKey string
+	// OnLineError, if set, is called for each non blank line that the Decoder could not parse as any kind of line
+	// defined by the benchmark spec.  The spec requires these lines be ignored, so they do not stop decoding.  This
+	// allows callers to notice truncated or corrupted benchmark output.
+	OnLineError func(err *LineError)
+	// OnUnits, if set, is called after each Unit line with the unit metadata of every Unit line so far.  This lets
+	// Stream callers see Unit lines after the last result, which no BenchmarkResult has.  units is shared with later
+	// results and must not be modified.  Test2JSONDecoder calls it with the unit metadata of the package of the line.
+	OnUnits func(units *UnitMetadata)
+	// Strict makes Decode and Stream return a *LineError for lines that have a valid benchmark name followed by other
+	// fields, but are not otherwise a valid benchmark result.  For example an odd number of fields, an iteration count
+	// that is not an integer, or a value that is not a float.  Lines that do not look like benchmark results are still
+	// ignored, as are lines that are only a benchmark name, which "go test -v" prints before each benchmark runs.
+	Strict bool
+	// Lossless makes Decode and Stream remember the original text of each line, including whitespace, line endings,
+	// and lines that are not part of the benchmark spec.  Encoder uses the original text to reproduce the input
+	// byte for byte when the decoded Run is not modified.  Values and iterations that were not modified keep their
+	// original spelling, such as "1.2e+06" or "7.250", even if other parts of a result were modified.
+	// Test2JSONDecoder ignores Lossless.
+	Lossless bool
+
 	keyValueDecoder        keyValueDecoder
 	benchmarkResultDecoder benchmarkResultDecoder
+	unitDecoder            unitDecoder
+	packageStatusDecoder   packageStatusDecoder
 }
 
 // keyValueDecoder is used by Decoder to help it configure how to decode key/value pairs of a benchmark result
@@ -25,8 +54,21 @@ type keyValueDecoder struct {
 type benchmarkResultDecoder struct {
 }
 
+// unitDecoder is used by Decoder to help it configure how to decode unit metadata lines
+type unitDecoder struct {
+}
+
+// packageStatusDecoder is used by Decoder to help it configure how to decode the lines go test prints when a package
+// finishes
+type packageStatusDecoder struct {
+}
+
 // Encoder allows converting a Run object back into a format defined by the benchmark spec.
 type Encoder struct {
+	// Align, if true, column aligns benchmark results the way the testing package prints them: names are left
+	// aligned and iterations and values are right aligned, with fields separated by tabs.  Results are aligned with
+	// the other results between the same configuration and unit lines.  Aligned output decodes to the same Run.
+	Align bool
 }
 
 // keyValue is a pair of key + value
@@ -41,30 +83,29 @@ type keyValue struct {
 // part of io.Reader, context is respected between reads from the input stream.  See Decode for more complete
 // documentation
 func (d Decoder) Stream(ctx context.Context, in io.Reader, onResult func(result BenchmarkResult)) error {
-	b := bufio.NewScanner(in)
-
-	// Values currentKeys and currentConfigurationIsDirty are used to share *OrderedStringStringMap objects
-	// between benchmark runs for efficiency.  Whenever currentKeys is dirty, it means any modification to that
-	// object first requires a deep copy.
-	currentKeys := new(OrderedStringStringMap)
-	currentConfigurationIsDirty := false
+	return d.stream(ctx, in, d.newDecodeState(onResult))
+}
 
+// stream decodes each line of in into state
+func (d Decoder) stream(ctx context.Context, in io.Reader, state *decodeState) error {
+	b := bufio.NewScanner(in)
+	if d.Lossless {
+		state.raw = newRawTracker()
+		b.Split(scanRawLines)
+	}
+	lineNumber := 0
 	for b.Scan() {
-		recentLine := b.Text()
-		kv, err := d.keyValueDecoder.decode(recentLine)
-		if err == nil {
-			if currentConfigurationIsDirty {
-				currentKeys = currentKeys.clone()
-				currentConfigurationIsDirty = false
-			}
-			currentKeys.add(kv.Key, kv.Value)
-			continue
+		lineNumber++
+		line := b.Text()
+		if state.raw != nil {
+			state.raw.startLine(line)
+			line = trimLineEnding(line)
 		}
-		brun, err := d.benchmarkResultDecoder.decode(recentLine)
-		if err == nil {
-			brun.Configuration = currentKeys
-			currentConfigurationIsDirty = true
-			onResult(*brun)
+		if err := state.decodeLine(lineNumber, line); err != nil {
+			return err
+		}
+		if state.raw != nil {
+			state.raw.endLine()
 		}
 		select {
 		case <-ctx.Done():
@@ -78,6 +119,178 @@ func (d Decoder) Stream(ctx context.Context, in io.Reader, onResult func(result
 	return nil
 }
 
+// decodeState is the state of a single decoded stream that carries over between lines
+type decodeState struct {
+	d        Decoder
+	onResult func(result BenchmarkResult)
+
+	// Values currentKeys and currentConfigurationIsDirty are used to share *OrderedStringStringMap objects
+	// between benchmark runs for efficiency.  Whenever currentKeys is dirty, it means any modification to that
+	// object first requires a deep copy.
+	currentKeys                 *OrderedStringStringMap
+	currentConfigurationIsDirty bool
+
+	// Values currentUnits and currentUnitsIsDirty share *UnitMetadata objects between benchmark runs the same way
+	// currentKeys does.  currentUnits is nil until the first Unit line.
+	currentUnits        *UnitMetadata
+	currentUnitsIsDirty bool
+	// currentProcs is the Configuration with ProcsKey of the last result that got one
+	currentProcs *procsConfiguration
+
+	// resultCount is the number of results decoded so far and sectionStart the first result of the package that
+	// has not finished yet.  pendingStatus is the status of a PASS or FAIL line that was not yet followed by the
+	// status line of the package.
+	resultCount   int
+	sectionStart  int
+	pendingStatus PackageStatus
+	packages      []PackageSection
+
+	// raw remembers the original text of the stream.  It is nil unless the Decoder is lossless.
+	raw *rawTracker
+}
+
+func (d Decoder) newDecodeState(onResult func(result BenchmarkResult)) *decodeState {
+	return &decodeState{
+		d:           d,
+		onResult:    onResult,
+		currentKeys: new(OrderedStringStringMap),
+	}
+}
+
+// decodeLine processes the next line of input, which should not include the trailing newline.  lineNumber is only
+// used to report errors.
+func (s *decodeState) decodeLine(lineNumber int, recentLine string) error {
+	kv, kvErr := s.d.keyValueDecoder.decode(recentLine)
+	if kvErr == nil {
+		if kv.Value != "" {
+			s.mutableKeys().add(kv.Key, kv.Value)
+		} else if _, exists := s.currentKeys.lookup(kv.Key); exists {
+			// A configuration line with an empty value clears the key
+			s.mutableKeys().remove(kv.Key)
+		}
+		return nil
+	}
+	unit, unitErr := s.d.unitDecoder.decode(recentLine)
+	if unitErr == nil {
+		if s.currentUnits == nil {
+			s.currentUnits = &UnitMetadata{}
+			s.currentUnitsIsDirty = false
+		} else if s.currentUnitsIsDirty {
+			s.currentUnits = s.currentUnits.Clone()
+			s.currentUnitsIsDirty = false
+		}
+		s.currentUnits.add(unit.Unit, unit.Metadata)
+		if s.d.OnUnits != nil {
+			s.d.OnUnits(s.currentUnits)
+			s.currentUnitsIsDirty = true
+		}
+		return nil
+	}
+	brun, err := s.d.benchmarkResultDecoder.decode(recentLine)
+	if err == nil {
+		brun.Configuration = s.currentKeys
+		if s.d.ProcsKey != "" {
+			s.setProcsKey(brun)
+		}
+		s.currentConfigurationIsDirty = true
+		brun.Units = s.currentUnits
+		s.currentUnitsIsDirty = true
+		s.resultCount++
+		if s.raw != nil {
+			s.raw.result(brun, recentLine)
+		}
+		s.onResult(*brun)
+		return nil
+	}
+	if status, statusErr := s.d.packageStatusDecoder.decode(recentLine); statusErr == nil {
+		s.finishPackage(status)
+		return nil
+	}
+	if strings.TrimSpace(recentLine) == "" {
+		return nil
+	}
+	if s.d.Strict && isNearMissBenchmarkResult(recentLine) {
+		return newLineError(lineNumber, recentLine, kvErr, unitErr, err)
+	}
+	if s.d.OnLineError != nil {
+		s.d.OnLineError(newLineError(lineNumber, recentLine, kvErr, unitErr, err))
+	}
+	return nil
+}
+
+// finishPackage records a line go test prints when a package finishes.  Lines without a package only remember the
+// status for a later line.
+func (s *decodeState) finishPackage(status *packageStatusLine) {
+	if status.Package == "" {
+		s.pendingStatus = status.Status
+		return
+	}
+	s.packages = append(s.packages, PackageSection{
+		Package:      status.Package,
+		Status:       status.Status,
+		Elapsed:      status.Elapsed,
+		Cached:       status.Cached,
+		ResultsStart: s.sectionStart,
+		ResultsEnd:   s.resultCount,
+	})
+	s.sectionStart = s.resultCount
+	s.pendingStatus = ""
+}
+
+// finish returns every package section of the stream.  Results after the last package status line, or a PASS or FAIL
+// line without a package status line, create a final section for the package named by the "pkg" configuration key.
+// Results after the last section that have neither a "pkg" key nor a PASS or FAIL line are not part of any package,
+// since there was no go test framing to say they are.
+func (s *decodeState) finish() []PackageSection {
+	if s.resultCount == s.sectionStart && s.pendingStatus == "" {
+		return s.packages
+	}
+	pkg, hasPkg := s.currentKeys.lookup("pkg")
+	if !hasPkg && s.pendingStatus == "" {
+		return s.packages
+	}
+	status := s.pendingStatus
+	if status == "" {
+		status = PackageStatusIncomplete
+	}
+	return append(s.packages, PackageSection{
+		Package:      pkg,
+		Status:       status,
+		ResultsStart: s.sectionStart,
+		ResultsEnd:   s.resultCount,
+	})
+}
+
+// mutableKeys returns currentKeys after making sure it is safe to modify
+func (s *decodeState) mutableKeys() *OrderedStringStringMap {
+	if s.currentConfigurationIsDirty {
+		s.currentKeys = s.currentKeys.Clone()
+		s.currentConfigurationIsDirty = false
+	}
+	return s.currentKeys
+}
+
+// setProcsKey sets the Configuration of result to the current configuration plus ProcsKey.  Results with the same
+// configuration and GOMAXPROCS share the same Configuration.  The current configuration is left as the input set it.
+func (s *decodeState) setProcsKey(result *BenchmarkResult) {
+	procs, hasProcs := result.Procs()
+	if _, exists := s.currentKeys.lookup(s.d.ProcsKey); exists || !hasProcs {
+		return
+	}
+	value := strconv.Itoa(procs)
+	if s.currentProcs == nil || s.currentProcs.input != s.currentKeys || s.currentProcs.value != value {
+		withProcs := s.currentKeys.Clone()
+		withProcs.add(s.d.ProcsKey, value)
+		s.currentProcs = &procsConfiguration{
+			input:     s.currentKeys,
+			withProcs: withProcs,
+			value:     value,
+		}
+	}
+	result.Configuration = s.currentProcs.withProcs
+	result.procs = s.currentProcs
+}
+
 // Decode an input stream into a benchmark run.  Returns an error if there are any issues decoding the benchmark,
 // for example from reading from in.  The returned run is **NOT** intended to be modified.  It contains public members
 // for API convenience, and will share OrderedStringStringMap values to reduce memory allocations.  Do not modify
@@ -85,18 +298,118 @@ func (d Decoder) Stream(ctx context.Context, in io.Reader, onResult func(result
 // assign values to it as you want.
 func (d Decoder) Decode(in io.Reader) (*Run, error) {
 	ret := &Run{}
-	if err := d.Stream(context.Background(), in, func(result BenchmarkResult) {
+	state := d.newDecodeState(func(result BenchmarkResult) {
 		ret.Results = append(ret.Results, result)
-	}); err != nil {
+	})
+	if err := d.stream(context.Background(), in, state); err != nil {
 		return nil, err
 	}
+	ret.Units = state.currentUnits
+	ret.Packages = state.finish()
+	ret.raw = state.raw.finish(state.currentUnits)
 	return ret, nil
 }
 
-var errNotEnoughFields = errors.New("invalid BenchmarkResult: not enough fields")
-var errNoPrefixBenchmark = errors.New("invalid BenchmarkResult: no prefix benchmark")
-var errUpperAfterBench = errors.New("invalid BenchmarkResult: no uppercase after benchmark name")
-var errEvenFields = errors.New("invalid BenchmarkResult: expect even number of fields")
+// LineKind is the kind of line, as defined by the benchmark spec, that a decoder tried to parse
+type LineKind int
+
+const (
+	// LineKindConfiguration is a "key: value" configuration line
+	LineKindConfiguration LineKind = iota
+	// LineKindBenchmarkResult is a "BenchmarkName iterations value unit..." benchmark result line
+	LineKindBenchmarkResult
+	// LineKindUnit is a "Unit unit key=value..." unit metadata line
+	LineKindUnit
+	// LineKindTest2JSONEvent is a JSON event line of "go test -json" output
+	LineKindTest2JSONEvent
+)
+
+func (l LineKind) String() string {
+	switch l {
+	case LineKindConfiguration:
+		return "configuration"
+	case LineKindBenchmarkResult:
+		return "benchmark result"
+	case LineKindUnit:
+		return "unit"
+	case LineKindTest2JSONEvent:
+		return "test2json event"
+	default:
+		return "LineKind(" + strconv.Itoa(int(l)) + ")"
+	}
+}
+
+// LineError describes a single line of input that could not be decoded
+type LineError struct {
+	// Line is the 1 based line number of the input
+	Line int
+	// Text is the raw text of the line, without the trailing newline
+	Text string
+	// Kind is the kind of line the decoder that rejected this line expected.  Lines that start with "Benchmark" are
+	// reported as rejected benchmark results and lines that start with "Unit" as rejected unit lines.  Everything else
+	// is reported as a rejected configuration line.
+	Kind LineKind
+	// Err is the reason the line was rejected.  It is one of the exported Err values of this package, or a *strconv.NumError
+	// for numbers that could not be parsed.
+	Err error
+}
+
+func (l *LineError) Error() string {
+	return fmt.Sprintf("line %d: invalid %s line %q: %s", l.Line, l.Kind, l.Text, l.Err)
+}
+
+// Unwrap returns the reason the line was rejected
+func (l *LineError) Unwrap() error {
+	return l.Err
+}
+
+// newLineError picks the decoder error most relevant to line.  Lines that start with Benchmark report the error from
+// the benchmark result decoder and lines that start with Unit the error from the unit decoder.
+func newLineError(lineNumber int, line string, kvErr error, unitErr error, resultErr error) *LineError {
+	ret := &LineError{
+		Line: lineNumber,
+		Text: line,
+		Kind: LineKindConfiguration,
+		Err:  kvErr,
+	}
+	trimmed := strings.TrimLeftFunc(line, unicode.IsSpace)
+	if strings.HasPrefix(trimmed, "Benchmark") {
+		ret.Kind = LineKindBenchmarkResult
+		ret.Err = resultErr
+	} else if unitErr != ErrUnitNoPrefix {
+		ret.Kind = LineKindUnit
+		ret.Err = unitErr
+	}
+	return ret
+}
+
+// isNearMissBenchmarkResult returns true if line starts with a valid benchmark name and has other fields.  It is
+// intended to be called on lines that did not decode as a benchmark result.
+func isNearMissBenchmarkResult(line string) bool {
+	fields := strings.Fields(line)
+	return len(fields) > 1 && isBenchmarkName(fields[0])
+}
+
+// isBenchmarkName returns true if name is a valid benchmark name according to the spec
+func isBenchmarkName(name string) bool {
+	// "The first field is the benchmark name, which must begin with Benchmark"
+	if !strings.HasPrefix(name, "Benchmark") {
+		return false
+	}
+	// "followed by an upper case character (as defined by unicode.IsUpper) or the end of the field, as in BenchmarkReverseString or just Benchmark."
+	return name == "Benchmark" || unicode.IsUpper(rune(name[len("Benchmark")]))
+}
+
+var (
+	// ErrNotEnoughFields is returned for benchmark result lines with fewer than four fields
+	ErrNotEnoughFields = errors.New("invalid BenchmarkResult: not enough fields")
+	// ErrNoPrefixBenchmark is returned for benchmark result lines that do not begin with Benchmark
+	ErrNoPrefixBenchmark = errors.New("invalid BenchmarkResult: no prefix benchmark")
+	// ErrUpperAfterBench is returned for benchmark result lines whose name does not continue with an upper case character
+	ErrUpperAfterBench = errors.New("invalid BenchmarkResult: no uppercase after benchmark name")
+	// ErrEvenFields is returned for benchmark result lines with an odd number of fields
+	ErrEvenFields = errors.New("invalid BenchmarkResult: expect even number of fields")
+)
 
 func (k *benchmarkResultDecoder) decode(kvLine string) (*BenchmarkResult, error) {
 	// https://github.com/golang/proposal/blob/master/design/14313-benchmark-format.md#benchmark-results
@@ -105,19 +418,19 @@ func (k *benchmarkResultDecoder) decode(kvLine string) (*BenchmarkResult, error)
 	fields := strings.Fields(kvLine)
 	// "The line must have an even number of fields, and at least four."
 	if len(fields) < 4 {
-		return nil, errNotEnoughFields
+		return nil, ErrNotEnoughFields
 	}
 	if len(fields)%2 != 0 {
-		return nil, errEvenFields
+		return nil, ErrEvenFields
 	}
 	// "The first field is the benchmark name, which must begin with Benchmark"
 	name := fields[0]
 	if !strings.HasPrefix(name, "Benchmark") {
-		return nil, errNoPrefixBenchmark
+		return nil, ErrNoPrefixBenchmark
 	}
 	// "followed by an upper case character (as defined by unicode.IsUpper) or the end of the field, as in BenchmarkReverseString or just Benchmark."
 	if name != "Benchmark" && !unicode.IsUpper(rune(name[len("Benchmark")])) {
-		return nil, errUpperAfterBench
+		return nil, ErrUpperAfterBench
 	}
 	// "The second field gives the number of iterations run"
 	iterations, err := strconv.Atoi(fields[1])
@@ -144,11 +457,18 @@ func (k *benchmarkResultDecoder) decode(kvLine string) (*BenchmarkResult, error)
 	return ret, nil
 }
 
-var errInvalidKeyValueLowercase = errors.New("invalid keyvalue: expect lowercase start")
-var errInvalidKeyValueEmpty = errors.New("invalid keyvalue: empty key")
-var errInvalidKeyValueSpaces = errors.New("invalid keyvalue: key has spaces or upper case")
-var errInvalidKeyNoColon = errors.New("invalid keyvalue: key has no colon")
-var errInvalidKeyValueReturn = errors.New("invalid keyvalue: value has newline")
+var (
+	// ErrInvalidKeyValueLowercase is returned for configuration lines whose key does not start with a lower case character
+	ErrInvalidKeyValueLowercase = errors.New("invalid keyvalue: expect lowercase start")
+	// ErrInvalidKeyValueEmpty is returned for configuration lines with an empty key
+	ErrInvalidKeyValueEmpty = errors.New("invalid keyvalue: empty key")
+	// ErrInvalidKeyValueSpaces is returned for configuration lines whose key contains spaces or upper case characters
+	ErrInvalidKeyValueSpaces = errors.New("invalid keyvalue: key has spaces or upper case")
+	// ErrInvalidKeyNoColon is returned for configuration lines without a colon
+	ErrInvalidKeyNoColon = errors.New("invalid keyvalue: key has no colon")
+	// ErrInvalidKeyValueReturn is returned for configuration lines whose value contains a newline
+	ErrInvalidKeyValueReturn = errors.New("invalid keyvalue: value has newline")
+)
 
 func (k *keyValueDecoder) decode(kvLine string) (*keyValue, error) {
 	// https://github.com/golang/proposal/blob/master/design/14313-benchmark-format.md#configuration-lines
@@ -156,7 +476,7 @@ func (k *keyValueDecoder) decode(kvLine string) (*keyValue, error) {
 	// "a key-value pair of the form `key: value`
 	firstColon := strings.Index(kvLine, ":")
 	if firstColon == -1 {
-		return nil, errInvalidKeyNoColon
+		return nil, ErrInvalidKeyNoColon
 	}
 	key := kvLine[:firstColon]
 	// Key can have spaces after the colon.  They should be removed.
@@ -166,21 +486,21 @@ func (k *keyValueDecoder) decode(kvLine string) (*keyValue, error) {
 	})
 	// "where key begins with a lower case character"
 	if len(key) == 0 {
-		return nil, errInvalidKeyValueEmpty
+		return nil, ErrInvalidKeyValueEmpty
 	}
 	// "where key begins with a lower case character (as defined by unicode.IsLower)"
 	if !unicode.IsLower(rune(key[0])) {
-		return nil, errInvalidKeyValueLowercase
+		return nil, ErrInvalidKeyValueLowercase
 	}
 	// "contains no space characters (as defined by unicode.IsSpace) nor upper case characters (as defined by unicode.IsUpper)"
 	if strings.IndexFunc(key, func(r rune) bool {
 		return unicode.IsSpace(r) || unicode.IsUpper(r)
 	}) != -1 {
-		return nil, errInvalidKeyValueSpaces
+		return nil, ErrInvalidKeyValueSpaces
 	}
 	// "There are no restrictions on value, except that it cannot contain a newline character"
 	if strings.Contains(value, "\n") {
-		return nil, errInvalidKeyValueReturn
+		return nil, ErrInvalidKeyValueReturn
 	}
 	return &keyValue{
 		Key:   key,
@@ -188,17 +508,264 @@ func (k *keyValueDecoder) decode(kvLine string) (*keyValue, error) {
 	}, nil
 }
 
+var (
+	// ErrUnitNoPrefix is returned for unit lines that do not begin with Unit
+	ErrUnitNoPrefix = errors.New("invalid unit: no prefix Unit")
+	// ErrUnitNotEnoughFields is returned for unit lines without a unit and at least one key=value pair
+	ErrUnitNotEnoughFields = errors.New("invalid unit: not enough fields")
+	// ErrUnitKeyValue is returned for unit lines with metadata that is not of the form key=value
+	ErrUnitKeyValue = errors.New("invalid unit: expect key=value metadata")
+)
+
+// unitLine is the decoded form of a unit metadata line
+type unitLine struct {
+	// Unit the metadata is for
+	Unit string
+	// Metadata are the key=value pairs of the line
+	Metadata *OrderedStringStringMap
+}
+
+func (u *unitDecoder) decode(line string) (*unitLine, error) {
+	// https://github.com/golang/proposal/blob/master/design/14313-benchmark-format.md
+	// Unit metadata lines are of the form "Unit unit key=value...".  Like benchmark results, the fields are separated
+	// by runs of space characters.
+	fields := strings.Fields(line)
+	if len(fields) == 0 || fields[0] != "Unit" {
+		return nil, ErrUnitNoPrefix
+	}
+	if len(fields) < 3 {
+		return nil, ErrUnitNotEnoughFields
+	}
+	ret := &unitLine{
+		Unit:     fields[1],
+		Metadata: &OrderedStringStringMap{},
+	}
+	for _, field := range fields[2:] {
+		sections := strings.SplitN(field, "=", 2)
+		if len(sections) != 2 || sections[0] == "" {
+			return nil, ErrUnitKeyValue
+		}
+		ret.Metadata.add(sections[0], sections[1])
+	}
+	return ret, nil
+}
+
+// errNoPackageStatus is returned for lines that are not printed by go test when a package finishes
+var errNoPackageStatus = errors.New("invalid package status: not a PASS, FAIL, ok or FAIL package line")
+
+// packageStatusLine is the decoded form of a line go test prints when a package finishes
+type packageStatusLine struct {
+	// Package is the import path of the package.  It is empty for lone PASS or FAIL lines.
+	Package string
+	// Status of the package
+	Status PackageStatus
+	// Elapsed is the time go test reported the package took
+	Elapsed time.Duration
+	// Cached is true if go test reported the result was cached
+	Cached bool
+}
+
+func (p *packageStatusDecoder) decode(line string) (*packageStatusLine, error) {
+	// The test binary prints a lone "PASS" or "FAIL" when it exits.  go test follows it with a line like
+	// "ok  \tgithub.com/cep21/benchparse\t0.006s" or "FAIL\tgithub.com/cep21/benchparse\t0.006s".  The status is
+	// always followed by a tab, which is how these are told apart from other output that starts with "ok".
+	trimmed := strings.TrimRightFunc(line, unicode.IsSpace)
+	switch trimmed {
+	case "PASS":
+		return &packageStatusLine{Status: PackageStatusOK}, nil
+	case "FAIL":
+		return &packageStatusLine{Status: PackageStatusFail}, nil
+	}
+	var status PackageStatus
+	switch {
+	case strings.HasPrefix(trimmed, "ok "), strings.HasPrefix(trimmed, "ok\t"):
+		status = PackageStatusOK
+	case strings.HasPrefix(trimmed, "FAIL\t"):
+		status = PackageStatusFail
+	default:
+		return nil, errNoPackageStatus
+	}
+	rest := trimmed[len(status):]
+	separator := rest[:len(rest)-len(strings.TrimLeft(rest, " \t"))]
+	if !strings.Contains(separator, "\t") {
+		return nil, errNoPackageStatus
+	}
+	fields := strings.Fields(rest)
+	ret := &packageStatusLine{
+		Package: fields[0],
+		Status:  status,
+	}
+	for _, field := range fields[1:] {
+		if field == "(cached)" {
+			ret.Cached = true
+			continue
+		}
+		if elapsed, err := time.ParseDuration(field); err == nil {
+			ret.Elapsed = elapsed
+		}
+	}
+	return ret, nil
+}
+
+// Encode writes run to w in the benchmark format.  Configuration and unit metadata lines are written only when they
+// change between results.  Runs from a lossless Decoder are written with their original text, except for the parts
+// that were modified.
 func (e *Encoder) Encode(w io.Writer, run *Run) error {
 	var previousConfig *OrderedStringStringMap
-	for _, r := range run.Results {
-		transition := previousConfig.valuesToTransition(r.Configuration)
-		for i := range transition.Order {
-			if _, err := fmt.Fprintf(w, "%s: %s\n", transition.Order[i], transition.Contents[transition.Order[i]]); err != nil {
+	var previousUnits *UnitMetadata
+	var previousRaw *rawResult
+	block := make([]BenchmarkResult, 0, len(run.Results))
+	for i, r := range run.Results {
+		config := r.encodedConfiguration()
+		if r.raw != nil && r.raw.state.matches(config, r.Units) &&
+			follows(r.raw.run, r.raw.index, previousRaw, i, previousConfig, previousUnits) {
+			if r.raw.preamble != "" {
+				if err := e.encodeResults(w, block, false); err != nil {
+					return err
+				}
+				block = block[:0]
+				if _, err := io.WriteString(w, r.raw.preamble); err != nil {
+					return err
+				}
+			}
+		} else {
+			configTransition := previousConfig.valuesToTransition(config)
+			unitTransition := previousUnits.valuesToTransition(r.Units)
+			if len(configTransition.Order) > 0 || len(unitTransition.Order) > 0 {
+				if err := e.encodeResults(w, block, false); err != nil {
+					return err
+				}
+				block = block[:0]
+			}
+			for _, k := range configTransition.Order {
+				if err := e.encodeKeyValue(w, k, configTransition.Contents[k]); err != nil {
+					return err
+				}
+			}
+			if err := e.encodeUnits(w, unitTransition); err != nil {
 				return err
 			}
 		}
-		previousConfig = r.Configuration
-		if _, err := fmt.Fprintf(w, "%s\n", r.String()); err != nil {
+		previousConfig = config
+		if r.Units != nil {
+			previousUnits = r.Units
+		}
+		previousRaw = r.raw
+		block = append(block, r)
+	}
+	if run.raw != nil && run.raw.units.equal(run.Units) &&
+		follows(run.raw, run.raw.resultCount, previousRaw, len(run.Results), previousConfig, previousUnits) {
+		if err := e.encodeResults(w, block, true); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, run.raw.trailer)
+		return err
+	}
+	if err := e.encodeResults(w, block, false); err != nil {
+		return err
+	}
+	return e.encodeUnits(w, previousUnits.valuesToTransition(run.Units))
+}
+
+// Minimum column widths used by Align.  These match the widths the testing package uses when it prints results.
+const (
+	minIterationsWidth = 8
+	minValueWidth      = 10
+)
+
+// encodeResults writes one line for each result in results, column aligning them if the encoder aligns output.
+// Results from a lossless Decoder that were not modified are written with their original text.  If atEnd is true,
+// results is the end of the original text, which might not end in a newline.
+func (e *Encoder) encodeResults(w io.Writer, results []BenchmarkResult, atEnd bool) error {
+	fields := make([][]string, len(results))
+	var widths []int
+	for i, r := range results {
+		if r.raw != nil && r.raw.unchangedLine(r) {
+			continue
+		}
+		fields[i] = r.raw.fields(r)
+		if !e.Align {
+			continue
+		}
+		for j, field := range fields[i] {
+			if j == len(widths) {
+				widths = append(widths, minColumnWidth(j))
+			}
+			widths[j] = maxInt(widths[j], len(field))
+		}
+	}
+	for i, r := range results {
+		var line string
+		switch {
+		case fields[i] == nil:
+			line = r.raw.line + r.raw.terminator
+			if r.raw.terminator == "" && !(atEnd && i == len(results)-1) {
+				line += "\n"
+			}
+		case e.Align:
+			line = alignFields(fields[i], widths) + "\n"
+		default:
+			line = strings.Join(fields[i], " ") + "\n"
+		}
+		if _, err := io.WriteString(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// minColumnWidth is the minimum width of the aligned column for the field at index i of a result line.  Units are
+// not aligned.
+func minColumnWidth(i int) int {
+	switch {
+	case i == 1:
+		return minIterationsWidth
+	case i > 1 && i%2 == 0:
+		return minValueWidth
+	default:
+		return 0
+	}
+}
+
+// alignFields joins the name, iterations, and value/unit fields of a result line so the name is left aligned and the
+// numbers are right aligned to widths
+func alignFields(fields []string, widths []int) string {
+	var line strings.Builder
+	fmt.Fprintf(&line, "%-*s\t%*s", widths[0], fields[0], widths[1], fields[1])
+	for j := 2; j+1 < len(fields); j += 2 {
+		fmt.Fprintf(&line, "\t%*s %s", widths[j], fields[j], fields[j+1])
+	}
+	return line.String()
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// encodeKeyValue writes a single configuration line.  Empty values, which clear the key, are written without the
+// space after the colon.
+func (e *Encoder) encodeKeyValue(w io.Writer, k string, v string) error {
+	if v == "" {
+		_, err := fmt.Fprintf(w, "%s:\n", k)
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%s: %s\n", k, v)
+	return err
+}
+
+// encodeUnits writes one Unit line for each unit inside units
+func (e *Encoder) encodeUnits(w io.Writer, units *UnitMetadata) error {
+	for _, unit := range units.Order {
+		metadata := units.Contents[unit]
+		parts := make([]string, 0, len(metadata.Order)+2)
+		parts = append(parts, "Unit", unit)
+		for _, k := range metadata.Order {
+			parts = append(parts, k+"="+metadata.Contents[k])
+		}
+		if _, err := fmt.Fprintf(w, "%s\n", strings.Join(parts, " ")); err != nil {
 			return err
 		}
 	}
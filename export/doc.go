@@ -0,0 +1,5 @@
+/*
+Package export writes decoded benchmark runs in the formats of monitoring and time series systems, so benchmark results
+can be graphed and alerted on next to other metrics.
+*/
+package export
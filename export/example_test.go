@@ -0,0 +1,29 @@
+package export_test
+
+import (
+	"os"
+	"strings"
+
+	"github.com/cep21/benchparse"
+	"github.com/cep21/benchparse/export"
+)
+
+func ExamplePrometheusEncoder_Encode() {
+	d := benchparse.Decoder{}
+	run, err := d.Decode(strings.NewReader(`commit: 7cd9055
+BenchmarkDecode/text=digits/size=1e4-8 100 154125 ns/op 64.88 MB/s
+`))
+	if err != nil {
+		panic(err)
+	}
+	e := export.PrometheusEncoder{}
+	if err := e.Encode(os.Stdout, run); err != nil {
+		panic(err)
+	}
+	// Output: # HELP benchmark_ns_per_op Benchmark result in ns/op.
+	// # TYPE benchmark_ns_per_op gauge
+	// benchmark_ns_per_op{benchmark="Decode/text=digits/size=1e4",procs="8",commit="7cd9055",text="digits",size="1e4"} 154125
+	// # HELP benchmark_MB_per_s Benchmark result in MB/s.
+	// # TYPE benchmark_MB_per_s gauge
+	// benchmark_MB_per_s{benchmark="Decode/text=digits/size=1e4",procs="8",commit="7cd9055",text="digits",size="1e4"} 64.88
+}
@@ -0,0 +1,143 @@
+package export
+
+import (
+	"errors"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cep21/benchparse"
+)
+
+// InfluxEncoder writes a Run in the InfluxDB line protocol, one point for each result.
+//
+// The measurement of a point is the benchmark function without the "Benchmark" prefix, followed by any sub benchmarks
+// that are not key=value pairs.  For example, the measurement of "BenchmarkEncode/fast/size=1e4-8" is "Encode/fast".
+// The tags of a point are the pairs of AllKeyValuePairs, and a "procs" tag for the "-N" suffix of the name if there
+// is one.  Tags are sorted by key, as InfluxDB prefers, and tags with an empty value are left out since InfluxDB does
+// not allow them.  Each value of the result is a float field named after its unit.  InfluxDB cannot store NaN or
+// infinite values, so those fields are left out, as are results without any other field.
+//
+// The timestamp of a point comes from the configuration key TimeKey, which is not also written as a tag.  It is
+// parsed as RFC 3339, as RFC 3339 with a "-0700" style offset, or as an integer number of seconds since the Unix epoch.
+// Points of results without the key have no timestamp, and InfluxDB uses the time it receives them.
+type InfluxEncoder struct {
+	// TimeKey is the configuration key of the timestamp of each point.  It defaults to "commit-time".
+	TimeKey string
+}
+
+// ErrInfluxTime is returned by InfluxEncoder for a timestamp that cannot be parsed
+var ErrInfluxTime = errors.New("invalid influx point: unparsable timestamp")
+
+const (
+	// defaultInfluxTimeKey is the configuration key of timestamps if InfluxEncoder.TimeKey is empty
+	defaultInfluxTimeKey = "commit-time"
+	// influxProcsTag is the tag of the "-N" suffix of the benchmark name
+	influxProcsTag = "procs"
+)
+
+// influxTimeLayouts are the layouts timestamps are parsed with, in order
+var influxTimeLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999Z0700"}
+
+// Encode writes run to w.  Returns ErrInfluxTime, and writes nothing, if any timestamp cannot be parsed.
+func (e *InfluxEncoder) Encode(w io.Writer, run *benchparse.Run) error {
+	var b strings.Builder
+	for _, r := range run.Results {
+		if err := e.encodePoint(&b, r); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// encodePoint writes the line of r to b.  Nothing is written for results without any field.
+func (e *InfluxEncoder) encodePoint(b *strings.Builder, r benchparse.BenchmarkResult) error {
+	timeKey := e.TimeKey
+	if timeKey == "" {
+		timeKey = defaultInfluxTimeKey
+	}
+	var timestamp string
+	if r.Configuration != nil {
+		if value, exists := r.Configuration.Contents[timeKey]; exists && value != "" {
+			t, err := parseInfluxTime(value)
+			if err != nil {
+				return err
+			}
+			timestamp = strconv.FormatInt(t.UnixNano(), 10)
+		}
+	}
+	fields := make([]string, 0, len(r.Values))
+	for _, v := range r.Values {
+		if math.IsNaN(v.Value) || math.IsInf(v.Value, 0) {
+			continue
+		}
+		fields = append(fields, influxKeyReplacer.Replace(v.Unit)+"="+strconv.FormatFloat(v.Value, 'f', -1, 64))
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	b.WriteString(influxMeasurementReplacer.Replace(influxMeasurement(r)))
+	for _, t := range influxTags(r, timeKey) {
+		b.WriteString("," + influxKeyReplacer.Replace(t.name) + "=" + influxKeyReplacer.Replace(t.value))
+	}
+	b.WriteString(" " + strings.Join(fields, ","))
+	if timestamp != "" {
+		b.WriteString(" " + timestamp)
+	}
+	b.WriteString("\n")
+	return nil
+}
+
+// influxMeasurement returns the benchmark function of r followed by its sub benchmarks that are not key=value pairs
+func influxMeasurement(r benchparse.BenchmarkResult) string {
+	name := r.ParsedName()
+	parts := []string{name.Function}
+	for _, sub := range name.SubBenchmarks {
+		if !strings.Contains(sub, "=") {
+			parts = append(parts, sub)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// influxTags returns the tags of r sorted by key, without the tag of timeKey
+func influxTags(r benchparse.BenchmarkResult, timeKey string) []label {
+	var ret []label
+	if procs, hasProcs := r.Procs(); hasProcs {
+		ret = setLabel(ret, influxProcsTag, strconv.Itoa(procs))
+	}
+	pairs := r.AllKeyValuePairs()
+	for _, k := range pairs.Order {
+		if k != timeKey {
+			ret = setLabel(ret, k, pairs.Contents[k])
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].name < ret[j].name
+	})
+	return ret
+}
+
+// parseInfluxTime parses a timestamp with each of influxTimeLayouts, and then as seconds since the Unix epoch
+func parseInfluxTime(value string) (time.Time, error) {
+	for _, layout := range influxTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	return time.Time{}, ErrInfluxTime
+}
+
+// influxMeasurementReplacer escapes measurements as the line protocol requires.  The line protocol cannot hold a
+// newline, so newlines are written as a literal "\n".
+var influxMeasurementReplacer = strings.NewReplacer(",", `\,`, " ", `\ `, "\n", `\n`)
+
+// influxKeyReplacer escapes tag keys, tag values, and field keys like influxMeasurementReplacer, and also escapes "="
+var influxKeyReplacer = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `, "\n", `\n`)
@@ -0,0 +1,78 @@
+package export
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/cep21/benchparse"
+	"github.com/cep21/benchparse/internal/benchtest"
+	"github.com/stretchr/testify/require"
+)
+
+func influxEncode(t *testing.T, e InfluxEncoder, run *benchparse.Run) string {
+	var buf bytes.Buffer
+	require.NoError(t, e.Encode(&buf, run))
+	return buf.String()
+}
+
+func TestInfluxEncoder_Encode(t *testing.T) {
+	run := benchtest.DecodeRun(t, `commit: 7cd9055
+commit-time: 2016-02-11T13:25:45-0500
+goos: darwin
+BenchmarkDecode/text=digits/level=speed/size=1e4-8 100 154125 ns/op 64.88 MB/s 40418 B/op 7 allocs/op
+BenchmarkEncode/fast 2000 1.5 ns/op
+commit-time:
+BenchmarkEncode/fast 2000 1.6 ns/op
+`)
+	require.Equal(t, `Decode,commit=7cd9055,goos=darwin,level=speed,procs=8,size=1e4,text=digits ns/op=154125,MB/s=64.88,B/op=40418,allocs/op=7 1455215145000000000
+Encode/fast,commit=7cd9055,goos=darwin ns/op=1.5 1455215145000000000
+Encode/fast,commit=7cd9055,goos=darwin ns/op=1.6
+`, influxEncode(t, InfluxEncoder{}, run))
+}
+
+func TestInfluxEncoder_Encode_timeKey(t *testing.T) {
+	run := benchtest.DecodeRun(t, `commit-time: 2016-02-11T13:25:45-0500
+date: 1455215145
+BenchmarkBob 1 1 ns/op
+date: 2016-02-11T18:25:45.5Z
+BenchmarkBob 1 2 ns/op
+`)
+	require.Equal(t, `Bob,commit-time=2016-02-11T13:25:45-0500 ns/op=1 1455215145000000000
+Bob,commit-time=2016-02-11T13:25:45-0500 ns/op=2 1455215145500000000
+`, influxEncode(t, InfluxEncoder{TimeKey: "date"}, run))
+
+	run = benchtest.DecodeRun(t, `commit-time: yesterday
+BenchmarkBob 1 1 ns/op
+`)
+	var buf bytes.Buffer
+	require.Equal(t, ErrInfluxTime, (&InfluxEncoder{}).Encode(&buf, run))
+	require.Empty(t, buf.String())
+}
+
+func TestInfluxEncoder_Encode_escape(t *testing.T) {
+	run := &benchparse.Run{
+		Results: []benchparse.BenchmarkResult{
+			{
+				Name:       "BenchmarkBob/a,b c/key=x,y",
+				Iterations: 1,
+				Values: []benchparse.ValueUnitPair{
+					{Value: 1, Unit: "my unit=x"},
+					{Value: math.NaN(), Unit: "nan/op"},
+					{Value: math.Inf(1), Unit: "inf/op"},
+				},
+				Configuration: &benchparse.OrderedStringStringMap{
+					Contents: map[string]string{"cpu": "Intel i7", "empty": "", "a=b": "c"},
+					Order:    []string{"cpu", "empty", "a=b"},
+				},
+			},
+			{
+				Name:       "BenchmarkJack",
+				Iterations: 1,
+				Values:     []benchparse.ValueUnitPair{{Value: math.NaN(), Unit: "ns/op"}},
+			},
+		},
+	}
+	require.Equal(t, `Bob/a\,b\ c,a\=b=c,cpu=Intel\ i7,key=x\,y my\ unit\=x=1
+`, influxEncode(t, InfluxEncoder{}, run))
+}
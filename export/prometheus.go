@@ -0,0 +1,290 @@
+package export
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/cep21/benchparse"
+)
+
+// PrometheusEncoder writes a Run in the Prometheus text exposition format, for example for the textfile collector of
+// node_exporter.  Each unit is a gauge metric family named after the unit, like benchmark_ns_per_op for ns/op.  Each
+// result is a sample of the family of each unit it reported.
+//
+// The "benchmark" label of a sample is the name of the benchmark without the "Benchmark" prefix and "-N" suffix, and
+// the "procs" label is the "-N" suffix if there is one.  Every configuration key, and every key=value sub benchmark of
+// the name, is also a label.  Label names are sanitized to the characters Prometheus allows.  Like AllKeyValuePairs,
+// sub benchmarks replace configuration keys with the same label name.  Keys named like a label the encoder adds
+// itself, such as "procs", get a "config_" prefix, like config_procs.  Different keys whose label names are the same
+// after sanitizing, like go-version and go.version, are told apart by a suffix in the order the keys first appear in
+// the run, like go_version and go_version_2.
+//
+// Units whose metric names are the same after sanitizing, like µs/op and us/op, are one family.  Each sample of such a
+// family gets a "unit" label with its unit.
+//
+// Prometheus does not allow two samples of a family with the same labels, which happens for benchmarks run with
+// -count.  Those samples get a "sample" label with the 0 based index of the sample.
+type PrometheusEncoder struct {
+	// Namespace is the prefix of each metric name.  It defaults to "benchmark".
+	Namespace string
+	// OpenMetrics ends the output with the "# EOF" line the OpenMetrics text format requires
+	OpenMetrics bool
+}
+
+const (
+	// prometheusBenchmarkLabel is the label of the benchmark name
+	prometheusBenchmarkLabel = "benchmark"
+	// prometheusProcsLabel is the label of the "-N" suffix of the benchmark name
+	prometheusProcsLabel = "procs"
+	// prometheusSampleLabel is the label that tells apart samples with otherwise the same labels
+	prometheusSampleLabel = "sample"
+	// prometheusUnitLabel is the label of the unit of samples of a family with more than one unit
+	prometheusUnitLabel = "unit"
+	// prometheusKeyPrefix is the prefix of keys named like one of the labels above
+	prometheusKeyPrefix = "config_"
+)
+
+// prometheusFamily is every sample of a single metric family
+type prometheusFamily struct {
+	name    string
+	units   []string
+	samples []prometheusSample
+}
+
+// prometheusSample is a single sample of a metric family
+type prometheusSample struct {
+	labels []label
+	unit   string
+	value  float64
+}
+
+// label is a single label of a sample
+type label struct {
+	name  string
+	value string
+}
+
+// Encode writes run to w
+func (p *PrometheusEncoder) Encode(w io.Writer, run *benchparse.Run) error {
+	var families []*prometheusFamily
+	familiesByName := make(map[string]*prometheusFamily)
+	names := newLabelNames()
+	for _, r := range run.Results {
+		labels := prometheusLabels(r, names)
+		for _, v := range r.Values {
+			name := p.metricName(v.Unit)
+			f, exists := familiesByName[name]
+			if !exists {
+				f = &prometheusFamily{name: name}
+				familiesByName[name] = f
+				families = append(families, f)
+			}
+			if !containsString(f.units, v.Unit) {
+				f.units = append(f.units, v.Unit)
+			}
+			f.samples = append(f.samples, prometheusSample{labels: labels, unit: v.Unit, value: v.Value})
+		}
+	}
+	var b strings.Builder
+	for _, f := range families {
+		b.WriteString("# HELP " + f.name + " " + escapeHelp("Benchmark result in "+strings.Join(f.units, ", ")+".") + "\n")
+		b.WriteString("# TYPE " + f.name + " gauge\n")
+		for _, s := range addSampleLabels(addUnitLabels(f)) {
+			b.WriteString(f.name + formatLabels(s.labels) + " " + formatPrometheusValue(s.value) + "\n")
+		}
+	}
+	if p.OpenMetrics {
+		b.WriteString("# EOF\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// unitNameReplacer spells the parts of a unit that Prometheus does not allow in a metric name
+var unitNameReplacer = strings.NewReplacer("/", "_per_", "µ", "u", "μ", "u")
+
+// metricName returns the name of the metric family of unit, like benchmark_ns_per_op for ns/op
+func (p *PrometheusEncoder) metricName(unit string) string {
+	namespace := p.Namespace
+	if namespace == "" {
+		namespace = "benchmark"
+	}
+	return sanitizeName(namespace + "_" + unitNameReplacer.Replace(unit))
+}
+
+// prometheusLabels returns the labels of every sample of r.  The benchmark and procs labels are first, followed by
+// the configuration keys and then the sub benchmarks.  names gives each key its label name.
+func prometheusLabels(r benchparse.BenchmarkResult, names *labelNames) []label {
+	name := r.ParsedName()
+	var keys []label
+	if r.Configuration != nil {
+		for _, k := range r.Configuration.Order {
+			keys = setLabel(keys, names.name(k), r.Configuration.Contents[k])
+		}
+	}
+	for _, sub := range name.SubBenchmarks {
+		sections := strings.SplitN(sub, "=", 2)
+		if len(sections) == 2 {
+			keys = setLabel(keys, names.name(sections[0]), sections[1])
+		}
+	}
+	ret := setLabel(nil, prometheusBenchmarkLabel, strings.Join(append([]string{name.Function}, name.SubBenchmarks...), "/"))
+	if name.HasProcs {
+		ret = setLabel(ret, prometheusProcsLabel, strconv.Itoa(name.Procs))
+	}
+	return append(ret, keys...)
+}
+
+// keyLabelName returns the label name of a configuration key or sub benchmark key.  Keys named like a label the
+// encoder adds itself get prometheusKeyPrefix.
+func keyLabelName(key string) string {
+	name := sanitizeName(key)
+	switch name {
+	case prometheusBenchmarkLabel, prometheusProcsLabel, prometheusSampleLabel, prometheusUnitLabel:
+		return prometheusKeyPrefix + name
+	}
+	return name
+}
+
+// labelNames are the label names of the keys of a single run.  Each key has a different label name, even if keyLabelName
+// is the same for more than one key.
+type labelNames struct {
+	byKey map[string]string
+	used  map[string]struct{}
+}
+
+func newLabelNames() *labelNames {
+	return &labelNames{
+		byKey: make(map[string]string),
+		used:  make(map[string]struct{}),
+	}
+}
+
+// name returns the label name of key.  It is keyLabelName of key, with a "_2", "_3", and so on suffix if an earlier
+// key already has that name.
+func (l *labelNames) name(key string) string {
+	if name, exists := l.byKey[key]; exists {
+		return name
+	}
+	base := keyLabelName(key)
+	name := base
+	for i := 2; ; i++ {
+		if _, exists := l.used[name]; !exists {
+			break
+		}
+		name = base + "_" + strconv.Itoa(i)
+	}
+	l.byKey[key] = name
+	l.used[name] = struct{}{}
+	return name
+}
+
+// addUnitLabels returns the samples of f, with a unit label if f has samples of more than one unit
+func addUnitLabels(f *prometheusFamily) []prometheusSample {
+	if len(f.units) < 2 {
+		return f.samples
+	}
+	ret := make([]prometheusSample, 0, len(f.samples))
+	for _, s := range f.samples {
+		labels := make([]label, 0, len(s.labels)+1)
+		labels = append(labels, s.labels...)
+		s.labels = setLabel(labels, prometheusUnitLabel, s.unit)
+		ret = append(ret, s)
+	}
+	return ret
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// setLabel sets the label called name to value.  Labels with an empty value are the same as no label to Prometheus,
+// so they are removed.
+func setLabel(labels []label, name string, value string) []label {
+	for i := range labels {
+		if labels[i].name == name {
+			labels = append(labels[:i], labels[i+1:]...)
+			break
+		}
+	}
+	if value == "" {
+		return labels
+	}
+	return append(labels, label{name: name, value: value})
+}
+
+// addSampleLabels adds a sample label to samples whose labels are the same as another sample
+func addSampleLabels(samples []prometheusSample) []prometheusSample {
+	counts := make(map[string]int)
+	for _, s := range samples {
+		counts[formatLabels(s.labels)]++
+	}
+	indexes := make(map[string]int)
+	ret := make([]prometheusSample, 0, len(samples))
+	for _, s := range samples {
+		key := formatLabels(s.labels)
+		if counts[key] > 1 {
+			labels := make([]label, 0, len(s.labels)+1)
+			labels = append(labels, s.labels...)
+			s.labels = setLabel(labels, prometheusSampleLabel, strconv.Itoa(indexes[key]))
+			indexes[key]++
+		}
+		ret = append(ret, s)
+	}
+	return ret
+}
+
+// formatLabels formats labels as {name="value",...}
+func formatLabels(labels []label) string {
+	parts := make([]string, 0, len(labels))
+	for _, l := range labels {
+		parts = append(parts, l.name+`="`+labelValueReplacer.Replace(l.value)+`"`)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// labelValueReplacer escapes label values as the exposition format requires
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// helpReplacer escapes HELP text as the exposition format requires
+var helpReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeHelp(help string) string {
+	return helpReplacer.Replace(help)
+}
+
+// formatPrometheusValue formats a sample value.  Prometheus spells infinity and NaN the same way Go does.
+func formatPrometheusValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// sanitizeName replaces every character Prometheus does not allow in metric and label names with an underscore, and
+// collapses repeated underscores.  Names that would start with a digit get a leading underscore.
+func sanitizeName(name string) string {
+	var b strings.Builder
+	lastUnderscore := false
+	for i, r := range name {
+		valid := r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9'
+		if i == 0 && r >= '0' && r <= '9' {
+			b.WriteRune('_')
+			b.WriteRune(r)
+			lastUnderscore = false
+			continue
+		}
+		if !valid {
+			r = '_'
+		}
+		if r == '_' && lastUnderscore {
+			continue
+		}
+		lastUnderscore = r == '_'
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,110 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cep21/benchparse"
+	"github.com/cep21/benchparse/internal/benchtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusEncoder_Encode(t *testing.T) {
+	run := benchtest.DecodeRun(t, `commit: 7cd9055
+goos: linux
+BenchmarkDecode/text=digits/level=speed/size=1e4-8 100 154125 ns/op 64.88 MB/s 40418 B/op 7 allocs/op
+BenchmarkEncode/fast 2000 1.5 ns/op
+`)
+	var buf bytes.Buffer
+	require.NoError(t, (&PrometheusEncoder{}).Encode(&buf, run))
+	require.Equal(t, `# HELP benchmark_ns_per_op Benchmark result in ns/op.
+# TYPE benchmark_ns_per_op gauge
+benchmark_ns_per_op{benchmark="Decode/text=digits/level=speed/size=1e4",procs="8",commit="7cd9055",goos="linux",text="digits",level="speed",size="1e4"} 154125
+benchmark_ns_per_op{benchmark="Encode/fast",commit="7cd9055",goos="linux"} 1.5
+# HELP benchmark_MB_per_s Benchmark result in MB/s.
+# TYPE benchmark_MB_per_s gauge
+benchmark_MB_per_s{benchmark="Decode/text=digits/level=speed/size=1e4",procs="8",commit="7cd9055",goos="linux",text="digits",level="speed",size="1e4"} 64.88
+# HELP benchmark_B_per_op Benchmark result in B/op.
+# TYPE benchmark_B_per_op gauge
+benchmark_B_per_op{benchmark="Decode/text=digits/level=speed/size=1e4",procs="8",commit="7cd9055",goos="linux",text="digits",level="speed",size="1e4"} 40418
+# HELP benchmark_allocs_per_op Benchmark result in allocs/op.
+# TYPE benchmark_allocs_per_op gauge
+benchmark_allocs_per_op{benchmark="Decode/text=digits/level=speed/size=1e4",procs="8",commit="7cd9055",goos="linux",text="digits",level="speed",size="1e4"} 7
+`, buf.String())
+}
+
+func TestPrometheusEncoder_Encode_samples(t *testing.T) {
+	run := benchtest.DecodeRun(t, `BenchmarkBob-8 100 10 ns/op
+BenchmarkBob-8 100 11 ns/op
+BenchmarkBob-4 100 12 ns/op
+`)
+	var buf bytes.Buffer
+	require.NoError(t, (&PrometheusEncoder{Namespace: "go_bench", OpenMetrics: true}).Encode(&buf, run))
+	require.Equal(t, `# HELP go_bench_ns_per_op Benchmark result in ns/op.
+# TYPE go_bench_ns_per_op gauge
+go_bench_ns_per_op{benchmark="Bob",procs="8",sample="0"} 10
+go_bench_ns_per_op{benchmark="Bob",procs="8",sample="1"} 11
+go_bench_ns_per_op{benchmark="Bob",procs="4"} 12
+# EOF
+`, buf.String())
+}
+
+func TestPrometheusEncoder_Encode_escape(t *testing.T) {
+	run := &benchparse.Run{
+		Results: []benchparse.BenchmarkResult{
+			{
+				Name:       "BenchmarkBob/9lives=yes/benchmark=override",
+				Iterations: 1,
+				Values:     []benchparse.ValueUnitPair{{Value: 1, Unit: "µs/op"}},
+				Configuration: &benchparse.OrderedStringStringMap{
+					Contents: map[string]string{"cpu": `Intel "i7"` + "\n" + `C:\`, "go-version": "1.13", "empty": ""},
+					Order:    []string{"cpu", "go-version", "empty"},
+				},
+			},
+		},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, (&PrometheusEncoder{}).Encode(&buf, run))
+	require.Equal(t, `# HELP benchmark_us_per_op Benchmark result in µs/op.
+# TYPE benchmark_us_per_op gauge
+benchmark_us_per_op{benchmark="Bob/9lives=yes/benchmark=override",cpu="Intel \"i7\"\nC:\\",go_version="1.13",_9lives="yes",config_benchmark="override"} 1
+`, buf.String())
+}
+
+func TestPrometheusEncoder_Encode_collisions(t *testing.T) {
+	run := benchtest.DecodeRun(t, `procs: many
+BenchmarkBob-8 100 10 µs/op 3 us/op
+BenchmarkBob/sample=a/unit=b-8 100 11 µs/op
+`)
+	var buf bytes.Buffer
+	require.NoError(t, (&PrometheusEncoder{}).Encode(&buf, run))
+	require.Equal(t, `# HELP benchmark_us_per_op Benchmark result in µs/op, us/op.
+# TYPE benchmark_us_per_op gauge
+benchmark_us_per_op{benchmark="Bob",procs="8",config_procs="many",unit="µs/op"} 10
+benchmark_us_per_op{benchmark="Bob",procs="8",config_procs="many",unit="us/op"} 3
+benchmark_us_per_op{benchmark="Bob/sample=a/unit=b",procs="8",config_procs="many",config_sample="a",config_unit="b",unit="µs/op"} 11
+`, buf.String())
+}
+
+func TestPrometheusEncoder_Encode_keyCollisions(t *testing.T) {
+	run := benchtest.DecodeRun(t, `go-version: 1.12
+go.version: 1.13
+BenchmarkBob 100 10 ns/op
+BenchmarkBob/go_version=a 100 11 ns/op
+`)
+	var buf bytes.Buffer
+	require.NoError(t, (&PrometheusEncoder{}).Encode(&buf, run))
+	require.Equal(t, `# HELP benchmark_ns_per_op Benchmark result in ns/op.
+# TYPE benchmark_ns_per_op gauge
+benchmark_ns_per_op{benchmark="Bob",go_version="1.12",go_version_2="1.13"} 10
+benchmark_ns_per_op{benchmark="Bob/go_version=a",go_version="1.12",go_version_2="1.13",go_version_3="a"} 11
+`, buf.String())
+}
+
+func TestSanitizeName(t *testing.T) {
+	require.Equal(t, "benchmark_ns_per_op", sanitizeName("benchmark_ns_per_op"))
+	require.Equal(t, "go_version", sanitizeName("go-version"))
+	require.Equal(t, "_9lives", sanitizeName("9lives"))
+	require.Equal(t, "a_b", sanitizeName("a__b"))
+	require.Equal(t, "a_b", sanitizeName("a.-b"))
+}
@@ -0,0 +1,27 @@
+/*
+Package filter selects benchmark results with expressions, similar in spirit to golang.org/x/perf/cmd/benchfilter.  A
+Filter can cut down a decoded Run, or wrap the onResult callback of Decoder.Stream so results that do not match are
+dropped as they are decoded and large logs never have to be held in memory.
+
+An expression is made of terms.  Terms next to each other, or joined by AND, must all match.  Terms joined by OR match
+if either does, and NOT or a leading "-" negates a term.  AND binds tighter than OR, and parentheses group terms.  "*"
+matches every result.  A term is one of
+
+	.name:pattern       the benchmark function, like "Decode" for "BenchmarkDecode/size=1e4-8"
+	.fullname:pattern   the name without the "Benchmark" prefix, like "Decode/size=1e4-8"
+	/key:pattern        the value of a key=value sub benchmark of the name, like /size:1e4
+	.unit:pattern       the values of a unit.  See below.
+	key:pattern         the value of a configuration key, like goos:linux
+	unit<number         a comparison of the value of a unit, like ns/op<1000.  The operators are <, <=, >, >=, ==
+	                    and !=.
+
+A pattern is a word, which matches exactly unless it has a "*" or "?" glob wildcard, a double quoted Go string, which
+matches exactly, or a regular expression between slashes, like /^digits|twain$/, which matches anywhere in the value
+unless anchored.  A key that is missing from a result has the value "", which only "" matches.  Results without a unit
+do not match comparisons of that unit.
+
+Terms select every value of a result, or none of them, with the exception of .unit terms.  Those only select the values
+of units that match, so ".unit:ns/op" keeps only the ns/op values of each result, and "-.unit:B/op" drops the B/op
+values.  A result matches if any of its values are selected, and only the selected values are kept.
+*/
+package filter
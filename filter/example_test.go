@@ -0,0 +1,28 @@
+package filter_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cep21/benchparse"
+	"github.com/cep21/benchparse/filter"
+)
+
+func ExampleFilter_Stream() {
+	f, err := filter.Parse(".name:Decode /size:1e4 .unit:ns/op")
+	if err != nil {
+		panic(err)
+	}
+	d := benchparse.Decoder{}
+	in := strings.NewReader(`BenchmarkDecode/text=digits/size=1e4-8 100 154125 ns/op 64.88 MB/s
+BenchmarkDecode/text=digits/size=1e6-8 1 13000839000 ns/op 76.92 MB/s
+BenchmarkEncode/size=1e4-8 100 144125 ns/op
+`)
+	if err := d.Stream(context.Background(), in, f.Stream(func(result benchparse.BenchmarkResult) {
+		fmt.Println(result)
+	})); err != nil {
+		panic(err)
+	}
+	// Output: BenchmarkDecode/text=digits/size=1e4-8 100 154125 ns/op
+}
@@ -0,0 +1,260 @@
+package filter
+
+import (
+	"strings"
+
+	"github.com/cep21/benchparse"
+)
+
+// Filter is a parsed filter expression.  See the package documentation for the syntax.  A Filter is safe to use from
+// multiple goroutines.
+type Filter struct {
+	expr string
+	root node
+}
+
+// Parse parses a filter expression.  Invalid expressions are returned as a *SyntaxError.
+func Parse(expr string) (*Filter, error) {
+	p := parser{expr: expr}
+	root, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return &Filter{expr: expr, root: root}, nil
+}
+
+// String returns the expression the filter was parsed from
+func (f *Filter) String() string {
+	return f.expr
+}
+
+// Match returns true if r matches the filter, along with r reduced to the values the filter selects.  r is returned as
+// is if every value is selected.  Otherwise the returned result has a new Values slice and shares everything else
+// with r.
+func (f *Filter) Match(r benchparse.BenchmarkResult) (benchparse.BenchmarkResult, bool) {
+	m := f.root.eval(newResultContext(r))
+	selected := 0
+	for _, v := range m {
+		if v {
+			selected++
+		}
+	}
+	if selected == 0 {
+		return r, false
+	}
+	if selected == len(r.Values) {
+		return r, true
+	}
+	values := make([]benchparse.ValueUnitPair, 0, selected)
+	for i, v := range r.Values {
+		if m[i] {
+			values = append(values, v)
+		}
+	}
+	r.Values = values
+	return r, true
+}
+
+// Run returns a new Run with only the results of run that match the filter, reduced to the values the filter selects.
+// Packages are kept, with their ranges of results adjusted to the results that are left.
+func (f *Filter) Run(run *benchparse.Run) *benchparse.Run {
+	ret := &benchparse.Run{
+		Units: run.Units,
+	}
+	// kept[i] is how many results of run before index i were kept
+	kept := make([]int, 0, len(run.Results)+1)
+	for _, r := range run.Results {
+		kept = append(kept, len(ret.Results))
+		if matched, ok := f.Match(r); ok {
+			ret.Results = append(ret.Results, matched)
+		}
+	}
+	kept = append(kept, len(ret.Results))
+	keptBefore := func(idx int) int {
+		if idx < 0 {
+			return 0
+		}
+		if idx >= len(kept) {
+			return len(ret.Results)
+		}
+		return kept[idx]
+	}
+	for _, p := range run.Packages {
+		p.ResultsStart = keptBefore(p.ResultsStart)
+		p.ResultsEnd = keptBefore(p.ResultsEnd)
+		ret.Packages = append(ret.Packages, p)
+	}
+	return ret
+}
+
+// Stream returns an onResult callback, for Decoder.Stream or Test2JSONDecoder.Stream, that calls onResult with the
+// results that match the filter, reduced to the values the filter selects.  Results that do not match are dropped.
+func (f *Filter) Stream(onResult func(result benchparse.BenchmarkResult)) func(result benchparse.BenchmarkResult) {
+	return func(result benchparse.BenchmarkResult) {
+		if matched, ok := f.Match(result); ok {
+			onResult(matched)
+		}
+	}
+}
+
+// mask is which values of a result are selected
+type mask []bool
+
+// newMask returns a mask of n values that are all selected or all not selected
+func newMask(n int, selected bool) mask {
+	ret := make(mask, n)
+	if selected {
+		for i := range ret {
+			ret[i] = true
+		}
+	}
+	return ret
+}
+
+// resultContext is a result being matched, with the parts of it terms look at parsed once
+type resultContext struct {
+	result benchparse.BenchmarkResult
+	name   benchparse.BenchmarkName
+}
+
+func newResultContext(r benchparse.BenchmarkResult) *resultContext {
+	return &resultContext{
+		result: r,
+		name:   r.ParsedName(),
+	}
+}
+
+// nameValue returns the value of the key=value sub benchmark of the name called key.  Like NameAsKeyValue, later sub
+// benchmarks win over earlier ones with the same key.
+func (c *resultContext) nameValue(key string) string {
+	ret := ""
+	for _, sub := range c.name.SubBenchmarks {
+		sections := strings.SplitN(sub, "=", 2)
+		if len(sections) == 2 && sections[0] == key {
+			ret = sections[1]
+		}
+	}
+	return ret
+}
+
+// node is a parsed part of an expression
+type node interface {
+	// eval returns the values of the result that the node selects
+	eval(c *resultContext) mask
+}
+
+type andNode struct {
+	left  node
+	right node
+}
+
+func (a andNode) eval(c *resultContext) mask {
+	ret := a.left.eval(c)
+	right := a.right.eval(c)
+	for i := range ret {
+		ret[i] = ret[i] && right[i]
+	}
+	return ret
+}
+
+type orNode struct {
+	left  node
+	right node
+}
+
+func (o orNode) eval(c *resultContext) mask {
+	ret := o.left.eval(c)
+	right := o.right.eval(c)
+	for i := range ret {
+		ret[i] = ret[i] || right[i]
+	}
+	return ret
+}
+
+type notNode struct {
+	n node
+}
+
+func (n notNode) eval(c *resultContext) mask {
+	ret := n.n.eval(c)
+	for i := range ret {
+		ret[i] = !ret[i]
+	}
+	return ret
+}
+
+type matchAllNode struct{}
+
+func (matchAllNode) eval(c *resultContext) mask {
+	return newMask(len(c.result.Values), true)
+}
+
+// keyKind is what part of a result a keyNode matches
+type keyKind int
+
+const (
+	keyName keyKind = iota
+	keyFullName
+	keyUnit
+	keyNameKey
+	keyConfiguration
+)
+
+// keyNode is a key:pattern term
+type keyNode struct {
+	kind  keyKind
+	key   string
+	match func(string) bool
+}
+
+func (k keyNode) eval(c *resultContext) mask {
+	var value string
+	switch k.kind {
+	case keyName:
+		value = c.name.Function
+	case keyFullName:
+		value = c.name.Base
+	case keyUnit:
+		ret := make(mask, len(c.result.Values))
+		for i, v := range c.result.Values {
+			ret[i] = k.match(v.Unit)
+		}
+		return ret
+	case keyNameKey:
+		value = c.nameValue(k.key)
+	case keyConfiguration:
+		if c.result.Configuration != nil {
+			value = c.result.Configuration.Contents[k.key]
+		}
+	}
+	return newMask(len(c.result.Values), k.match(value))
+}
+
+// compareNode is a unit<number term
+type compareNode struct {
+	unit  string
+	op    string
+	value float64
+}
+
+func (n compareNode) eval(c *resultContext) mask {
+	v, exists := c.result.ValueByUnit(n.unit)
+	return newMask(len(c.result.Values), exists && n.compare(v))
+}
+
+func (n compareNode) compare(v float64) bool {
+	switch n.op {
+	case "<":
+		return v < n.value
+	case "<=":
+		return v <= n.value
+	case ">":
+		return v > n.value
+	case ">=":
+		return v >= n.value
+	case "==":
+		return v == n.value
+	default:
+		return v != n.value
+	}
+}
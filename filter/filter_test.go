@@ -0,0 +1,147 @@
+package filter
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cep21/benchparse"
+	"github.com/cep21/benchparse/internal/benchtest"
+	"github.com/stretchr/testify/require"
+)
+
+const example = `commit: 7cd9055
+goos: linux
+cpu: Intel(R) Core(TM) i7-4980HQ CPU @ 2.80GHz
+BenchmarkDecode/text=digits/level=speed/size=1e4-8 100 154125 ns/op 64.88 MB/s 40418 B/op
+BenchmarkDecode/text=twain/level=speed/size=1e6-8 1 13000839000 ns/op 76.92 MB/s 4001776 B/op
+goos: darwin
+BenchmarkEncode/fast-8 2000 1.5 ns/op
+BenchmarkEncode/slow-4 2000 2500 ns/op
+ok  	github.com/cep21/a	1.2s
+`
+
+// names returns the full name of each result of run
+func names(run *benchparse.Run) []string {
+	ret := make([]string, 0, len(run.Results))
+	for _, r := range run.Results {
+		ret = append(ret, r.Name)
+	}
+	return ret
+}
+
+func TestFilter_Run(t *testing.T) {
+	run := benchtest.DecodeRun(t, example)
+	matches := func(expr string, expected ...string) func(t *testing.T) {
+		return func(t *testing.T) {
+			f, err := Parse(expr)
+			require.NoError(t, err)
+			require.Equal(t, expr, f.String())
+			filtered := f.Run(run)
+			if len(expected) == 0 {
+				require.Empty(t, filtered.Results)
+				return
+			}
+			require.Equal(t, expected, names(filtered))
+		}
+	}
+	decodeDigits := "BenchmarkDecode/text=digits/level=speed/size=1e4-8"
+	decodeTwain := "BenchmarkDecode/text=twain/level=speed/size=1e6-8"
+	encodeFast := "BenchmarkEncode/fast-8"
+	encodeSlow := "BenchmarkEncode/slow-4"
+	t.Run("case=all", matches("*", decodeDigits, decodeTwain, encodeFast, encodeSlow))
+	t.Run("case=name", matches(".name:Encode", encodeFast, encodeSlow))
+	t.Run("case=namenoprefix", matches(".name:BenchmarkEncode"))
+	t.Run("case=fullname", matches(".fullname:Encode/fast-8", encodeFast))
+	t.Run("case=fullnameglob", matches(".fullname:*/fast-?", encodeFast))
+	t.Run("case=namekey", matches("/text:twain", decodeTwain))
+	t.Run("case=namekeyregexp", matches(`/size:/^1e\d$/`, decodeDigits, decodeTwain))
+	t.Run("case=namekeymissing", matches(`/text:""`, encodeFast, encodeSlow))
+	t.Run("case=configuration", matches("goos:darwin", encodeFast, encodeSlow))
+	t.Run("case=configurationquoted", matches(`cpu:"Intel(R) Core(TM) i7-4980HQ CPU @ 2.80GHz"`, decodeDigits, decodeTwain, encodeFast, encodeSlow))
+	t.Run("case=configurationmissing", matches("gomaxprocs:8"))
+	t.Run("case=and", matches(".name:Decode /text:digits", decodeDigits))
+	t.Run("case=andkeyword", matches(".name:Decode AND /text:digits", decodeDigits))
+	t.Run("case=or", matches("/text:twain OR .fullname:*fast*", decodeTwain, encodeFast))
+	t.Run("case=precedence", matches("goos:darwin OR .name:Decode /text:twain", decodeTwain, encodeFast, encodeSlow))
+	t.Run("case=parens", matches("(goos:darwin OR .name:Decode) -ns/op>1000", encodeFast))
+	t.Run("case=not", matches("NOT .name:Decode", encodeFast, encodeSlow))
+	t.Run("case=notnot", matches("NOT -.name:Decode", decodeDigits, decodeTwain))
+	t.Run("case=less", matches("ns/op<1000", encodeFast))
+	t.Run("case=lessequal", matches("ns/op<=2500", encodeFast, encodeSlow))
+	t.Run("case=greater", matches("MB/s>70", decodeTwain))
+	t.Run("case=greaterequal", matches("B/op>=40418", decodeDigits, decodeTwain))
+	t.Run("case=equal", matches("ns/op==1.5", encodeFast))
+	t.Run("case=notequal", matches("ns/op!=1.5", decodeDigits, decodeTwain, encodeSlow))
+	t.Run("case=missingunit", matches("MB/s<1e9", decodeDigits, decodeTwain))
+	t.Run("case=unit", matches(".unit:MB/s", decodeDigits, decodeTwain))
+	t.Run("case=notunit", matches("-.unit:ns/op", decodeDigits, decodeTwain))
+}
+
+func TestFilter_Match_values(t *testing.T) {
+	run := benchtest.DecodeRun(t, example)
+	values := func(expr string, expected ...string) func(t *testing.T) {
+		return func(t *testing.T) {
+			f, err := Parse(expr)
+			require.NoError(t, err)
+			r, ok := f.Match(run.Results[0])
+			require.Equal(t, len(expected) > 0, ok)
+			if !ok {
+				return
+			}
+			units := make([]string, 0, len(r.Values))
+			for _, v := range r.Values {
+				units = append(units, v.Unit)
+			}
+			require.Equal(t, expected, units)
+			require.Equal(t, run.Results[0].Configuration, r.Configuration)
+		}
+	}
+	t.Run("case=all", values("*", "ns/op", "MB/s", "B/op"))
+	t.Run("case=unit", values(".unit:ns/op", "ns/op"))
+	t.Run("case=unitglob", values(".unit:*/op", "ns/op", "B/op"))
+	t.Run("case=unitregexp", values(`.unit:/\/s$/`, "MB/s"))
+	t.Run("case=notunit", values("-.unit:B/op", "ns/op", "MB/s"))
+	t.Run("case=unitor", values(".unit:ns/op OR .unit:B/op", "ns/op", "B/op"))
+	t.Run("case=unitand", values(".unit:ns/op .name:Decode", "ns/op"))
+	t.Run("case=unitandmismatch", values(".unit:ns/op .name:Encode"))
+	t.Run("case=unitcomparison", values(".unit:B/op ns/op>1000", "B/op"))
+	t.Run("case=unitnone", values(".unit:allocs/op"))
+
+	f, err := Parse(".unit:ns/op")
+	require.NoError(t, err)
+	_, _ = f.Match(run.Results[0])
+	require.Len(t, run.Results[0].Values, 3)
+}
+
+func TestFilter_Run_packages(t *testing.T) {
+	run := benchtest.DecodeRun(t, `BenchmarkBob 1 1 ns/op
+ok  	github.com/cep21/a	1.2s
+BenchmarkJack 1 1 ns/op
+BenchmarkBob 1 2 ns/op
+ok  	github.com/cep21/b	1.2s
+`)
+	f, err := Parse(".name:Bob")
+	require.NoError(t, err)
+	filtered := f.Run(run)
+	require.Len(t, filtered.Results, 2)
+	require.Len(t, filtered.Packages, 2)
+	require.Equal(t, 0, filtered.Packages[0].ResultsStart)
+	require.Equal(t, 1, filtered.Packages[0].ResultsEnd)
+	require.Equal(t, 1, filtered.Packages[1].ResultsStart)
+	require.Equal(t, 2, filtered.Packages[1].ResultsEnd)
+	require.Equal(t, "github.com/cep21/b", filtered.Packages[1].Package)
+}
+
+func TestFilter_Stream(t *testing.T) {
+	f, err := Parse("goos:darwin .unit:ns/op")
+	require.NoError(t, err)
+	var results []benchparse.BenchmarkResult
+	d := benchparse.Decoder{}
+	require.NoError(t, d.Stream(context.Background(), strings.NewReader(example), f.Stream(func(result benchparse.BenchmarkResult) {
+		results = append(results, result)
+	})))
+	require.Len(t, results, 2)
+	require.Equal(t, "BenchmarkEncode/fast-8", results[0].Name)
+	require.Equal(t, "BenchmarkEncode/slow-4", results[1].Name)
+}
@@ -0,0 +1,342 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	// ErrUnexpectedEnd is returned for expressions that end before a term is complete
+	ErrUnexpectedEnd = errors.New("invalid filter: unexpected end of expression")
+	// ErrUnexpectedToken is returned for text that is not valid where it appears in the expression
+	ErrUnexpectedToken = errors.New("invalid filter: unexpected token")
+	// ErrUnterminated is returned for double quoted strings or regular expressions without a closing quote or slash
+	ErrUnterminated = errors.New("invalid filter: unterminated string or regular expression")
+	// ErrUnknownKey is returned for keys that start with "." but are not .name, .fullname, or .unit
+	ErrUnknownKey = errors.New("invalid filter: unknown key")
+)
+
+// SyntaxError describes why an expression could not be parsed
+type SyntaxError struct {
+	// Offset is the byte offset into the expression of the invalid text
+	Offset int
+	// Err is the reason the expression was rejected.  It is one of the exported Err values of this package, a
+	// *strconv.NumError for numbers that could not be parsed, or a *syntax.Error for invalid regular expressions.
+	Err error
+}
+
+func (s *SyntaxError) Error() string {
+	return fmt.Sprintf("offset %d: %s", s.Offset, s.Err)
+}
+
+// Unwrap returns the reason the expression was rejected
+func (s *SyntaxError) Unwrap() error {
+	return s.Err
+}
+
+// Keys with a special meaning inside an expression
+const (
+	nameKey     = ".name"
+	fullNameKey = ".fullname"
+	unitKey     = ".unit"
+)
+
+// parser is a recursive descent parser of a single expression
+type parser struct {
+	expr string
+	pos  int
+}
+
+// parse returns the node of the whole expression
+func (p *parser) parse() (node, error) {
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.expr) {
+		return nil, p.errorf(ErrUnexpectedToken)
+	}
+	return n, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.skipSpace(); p.consumeKeyword("OR"); p.skipSpace() {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if p.pos == len(p.expr) || p.expr[p.pos] == ')' || p.peekKeyword("OR") {
+			return left, nil
+		}
+		p.consumeKeyword("AND")
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	p.skipSpace()
+	if p.pos == len(p.expr) {
+		return nil, p.errorf(ErrUnexpectedEnd)
+	}
+	switch {
+	case p.consumeKeyword("NOT"):
+		n, err := p.parseUnary()
+		return notNode{n: n}, err
+	case p.expr[p.pos] == '-':
+		p.pos++
+		n, err := p.parseUnary()
+		return notNode{n: n}, err
+	case p.expr[p.pos] == '(':
+		p.pos++
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.pos == len(p.expr) {
+			return nil, p.errorf(ErrUnexpectedEnd)
+		}
+		if p.expr[p.pos] != ')' {
+			return nil, p.errorf(ErrUnexpectedToken)
+		}
+		p.pos++
+		return n, nil
+	case p.consumeKeyword("*"):
+		return matchAllNode{}, nil
+	}
+	return p.parseTerm()
+}
+
+// parseTerm parses a key:pattern or unit<number term
+func (p *parser) parseTerm() (node, error) {
+	start := p.pos
+	key := p.readWhile(func(r rune) bool {
+		return !unicode.IsSpace(r) && !strings.ContainsRune("():<>=!", r)
+	})
+	if key == "" {
+		return nil, p.errorf(ErrUnexpectedToken)
+	}
+	if p.pos == len(p.expr) {
+		return nil, p.errorf(ErrUnexpectedEnd)
+	}
+	if p.expr[p.pos] != ':' {
+		if key[0] == '.' || key[0] == '/' {
+			return nil, p.errorf(ErrUnexpectedToken)
+		}
+		return p.parseComparison(key)
+	}
+	p.pos++
+	var ret keyNode
+	switch {
+	case key == nameKey:
+		ret.kind = keyName
+	case key == fullNameKey:
+		ret.kind = keyFullName
+	case key == unitKey:
+		ret.kind = keyUnit
+	case key[0] == '.':
+		return nil, &SyntaxError{Offset: start, Err: ErrUnknownKey}
+	case key[0] == '/':
+		ret.kind = keyNameKey
+		ret.key = key[1:]
+	default:
+		ret.kind = keyConfiguration
+		ret.key = key
+	}
+	var err error
+	ret.match, err = p.parsePattern()
+	return ret, err
+}
+
+// comparisonOperators are the operators of a comparison, with longer operators before their prefixes
+var comparisonOperators = []string{"<=", ">=", "==", "!=", "<", ">"}
+
+// parseComparison parses the operator and number of a comparison of unit
+func (p *parser) parseComparison(unit string) (node, error) {
+	ret := compareNode{unit: unit}
+	for _, op := range comparisonOperators {
+		if strings.HasPrefix(p.expr[p.pos:], op) {
+			ret.op = op
+			break
+		}
+	}
+	if ret.op == "" {
+		return nil, p.errorf(ErrUnexpectedToken)
+	}
+	p.pos += len(ret.op)
+	start := p.pos
+	number := p.readWord()
+	if number == "" {
+		return nil, p.emptyWordError()
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return nil, &SyntaxError{Offset: start, Err: err}
+	}
+	ret.value = value
+	return ret, nil
+}
+
+// parsePattern parses a word, double quoted string, or regular expression
+func (p *parser) parsePattern() (func(string) bool, error) {
+	if p.pos == len(p.expr) {
+		return nil, p.errorf(ErrUnexpectedEnd)
+	}
+	start := p.pos
+	switch p.expr[p.pos] {
+	case '"':
+		s, err := p.readDelimited('"')
+		if err != nil {
+			return nil, err
+		}
+		unquoted, err := strconv.Unquote(`"` + s + `"`)
+		if err != nil {
+			return nil, &SyntaxError{Offset: start, Err: err}
+		}
+		return exactMatch(unquoted), nil
+	case '/':
+		s, err := p.readDelimited('/')
+		if err != nil {
+			return nil, err
+		}
+		re, err := regexp.Compile(strings.Replace(s, `\/`, "/", -1))
+		if err != nil {
+			return nil, &SyntaxError{Offset: start, Err: err}
+		}
+		return re.MatchString, nil
+	}
+	word := p.readWord()
+	if word == "" {
+		return nil, p.emptyWordError()
+	}
+	if !strings.ContainsAny(word, "*?") {
+		return exactMatch(word), nil
+	}
+	return globMatch(word), nil
+}
+
+// readDelimited reads the text between the delimiter at the current position and the next delimiter that is not
+// escaped by a backslash.  The text is returned as is, with any escapes.
+func (p *parser) readDelimited(delimiter byte) (string, error) {
+	start := p.pos
+	for i := p.pos + 1; i < len(p.expr); i++ {
+		switch p.expr[i] {
+		case '\\':
+			i++
+		case delimiter:
+			p.pos = i + 1
+			return p.expr[start+1 : i], nil
+		}
+	}
+	return "", &SyntaxError{Offset: start, Err: ErrUnterminated}
+}
+
+// readWord reads text up to the next white space or parenthesis
+func (p *parser) readWord() string {
+	return p.readWhile(func(r rune) bool {
+		return !unicode.IsSpace(r) && r != '(' && r != ')'
+	})
+}
+
+// emptyWordError is the error for a word that was expected at the current position but is empty
+func (p *parser) emptyWordError() error {
+	if p.pos == len(p.expr) {
+		return p.errorf(ErrUnexpectedEnd)
+	}
+	return p.errorf(ErrUnexpectedToken)
+}
+
+func (p *parser) readWhile(f func(r rune) bool) string {
+	start := p.pos
+	for p.pos < len(p.expr) {
+		r, size := utf8.DecodeRuneInString(p.expr[p.pos:])
+		if !f(r) {
+			break
+		}
+		p.pos += size
+	}
+	return p.expr[start:p.pos]
+}
+
+func (p *parser) skipSpace() {
+	p.readWhile(unicode.IsSpace)
+}
+
+// peekKeyword returns true if the expression continues with keyword, followed by white space, a parenthesis, or the
+// end of the expression
+func (p *parser) peekKeyword(keyword string) bool {
+	if !strings.HasPrefix(p.expr[p.pos:], keyword) {
+		return false
+	}
+	rest := p.expr[p.pos+len(keyword):]
+	if rest == "" {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(rest)
+	return unicode.IsSpace(r) || r == '(' || r == ')'
+}
+
+// consumeKeyword moves past keyword if peekKeyword is true
+func (p *parser) consumeKeyword(keyword string) bool {
+	if !p.peekKeyword(keyword) {
+		return false
+	}
+	p.pos += len(keyword)
+	return true
+}
+
+func (p *parser) errorf(err error) error {
+	return &SyntaxError{Offset: p.pos, Err: err}
+}
+
+// exactMatch returns a matcher of values equal to pattern
+func exactMatch(pattern string) func(string) bool {
+	return func(s string) bool {
+		return s == pattern
+	}
+}
+
+// globMatch returns a matcher of values that match pattern, where "*" is any text and "?" is any single character.
+// Unlike path.Match, "*" also matches "/".
+func globMatch(pattern string) func(string) bool {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String()).MatchString
+}
@@ -0,0 +1,65 @@
+package filter
+
+import (
+	"regexp/syntax"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_errors(t *testing.T) {
+	invalid := func(expr string, offset int, expected error) func(t *testing.T) {
+		return func(t *testing.T) {
+			f, err := Parse(expr)
+			require.Nil(t, f)
+			require.IsType(t, &SyntaxError{}, err)
+			syntaxErr := err.(*SyntaxError)
+			require.Equal(t, offset, syntaxErr.Offset)
+			require.Equal(t, expected, syntaxErr.Unwrap())
+		}
+	}
+	t.Run("case=empty", invalid("", 0, ErrUnexpectedEnd))
+	t.Run("case=space", invalid("   ", 3, ErrUnexpectedEnd))
+	t.Run("case=bareword", invalid("Decode", 6, ErrUnexpectedEnd))
+	t.Run("case=barewordand", invalid("Decode goos:linux", 6, ErrUnexpectedToken))
+	t.Run("case=nopattern", invalid("goos:", 5, ErrUnexpectedEnd))
+	t.Run("case=nopatternparen", invalid("(goos:)", 6, ErrUnexpectedToken))
+	t.Run("case=trailingor", invalid("goos:linux OR", 13, ErrUnexpectedEnd))
+	t.Run("case=trailingnot", invalid("NOT", 3, ErrUnexpectedEnd))
+	t.Run("case=unclosedparen", invalid("(goos:linux", 11, ErrUnexpectedEnd))
+	t.Run("case=extraparen", invalid("goos:linux)", 10, ErrUnexpectedToken))
+	t.Run("case=emptyparen", invalid("()", 1, ErrUnexpectedToken))
+	t.Run("case=unterminatedquote", invalid(`goos:"linux`, 5, ErrUnterminated))
+	t.Run("case=unterminatedregexp", invalid(`goos:/lin\/ux`, 5, ErrUnterminated))
+	t.Run("case=unknownkey", invalid(".procs:8", 0, ErrUnknownKey))
+	t.Run("case=specialcomparison", invalid(".name<5", 5, ErrUnexpectedToken))
+	t.Run("case=nooperator", invalid("ns/op=5", 5, ErrUnexpectedToken))
+	t.Run("case=nonumber", invalid("ns/op<", 6, ErrUnexpectedEnd))
+
+	_, err := Parse("ns/op<fast")
+	require.IsType(t, &strconv.NumError{}, err.(*SyntaxError).Err)
+	require.Equal(t, `offset 6: strconv.ParseFloat: parsing "fast": invalid syntax`, err.Error())
+	_, err = Parse("goos:/(/")
+	require.IsType(t, &syntax.Error{}, err.(*SyntaxError).Err)
+	require.Equal(t, 5, err.(*SyntaxError).Offset)
+	_, err = Parse(`goos:"\q"`)
+	require.Equal(t, strconv.ErrSyntax, err.(*SyntaxError).Err)
+}
+
+func TestParse_keywords(t *testing.T) {
+	// Keywords are only keywords when they stand alone
+	for _, expr := range []string{"OR:x", "NOT:x", "AND:x", "ORDER:x", "-OR:x", "(OR:x)", "* OR:x", "a:b ANDx:y"} {
+		_, err := Parse(expr)
+		require.NoError(t, err, expr)
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	require.True(t, globMatch("*/op")("ns/op"))
+	require.True(t, globMatch("a?c")("abc"))
+	require.False(t, globMatch("a?c")("abbc"))
+	require.False(t, globMatch("a.c")("abc"))
+	require.True(t, globMatch("a.c*")("a.cd"))
+	require.False(t, globMatch("*/op")("ns/op/x"))
+}
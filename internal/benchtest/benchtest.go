@@ -0,0 +1,20 @@
+// Package benchtest holds helpers shared by the tests of the packages of benchparse
+package benchtest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cep21/benchparse"
+)
+
+// DecodeRun decodes in with a default Decoder, failing t if in is not valid
+func DecodeRun(t testing.TB, in string) *benchparse.Run {
+	t.Helper()
+	d := benchparse.Decoder{}
+	run, err := d.Decode(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("decoding %q: %s", in, err)
+	}
+	return run
+}
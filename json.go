@@ -0,0 +1,337 @@
+package benchparse
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+)
+
+// JSONVersion is the version of the JSON schema Run is marshaled with.  It only changes if the schema changes in a
+// way older readers cannot ignore.
+const JSONVersion = 1
+
+var (
+	// ErrJSONVersion is returned when unmarshaling a Run with a JSON schema version newer than JSONVersion
+	ErrJSONVersion = errors.New("invalid json: unsupported version")
+	// ErrJSONReference is returned when unmarshaling a Run that refers to a configuration or unit metadata that is not
+	// in its tables, or to results that are not in the run
+	ErrJSONReference = errors.New("invalid json: reference out of range")
+)
+
+// jsonPair is a single key/value pair of an OrderedStringStringMap
+type jsonPair struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// MarshalJSON encodes the map as an array of {"key": k, "value": v} objects in order, since a JSON object does not
+// keep the order of its keys.
+func (o OrderedStringStringMap) MarshalJSON() ([]byte, error) {
+	pairs := make([]jsonPair, 0, len(o.Order))
+	for _, k := range o.Order {
+		pairs = append(pairs, jsonPair{Key: k, Value: o.Contents[k]})
+	}
+	return json.Marshal(pairs)
+}
+
+// UnmarshalJSON decodes an array of {"key": k, "value": v} objects.  Later pairs overwrite earlier pairs with the
+// same key.
+func (o *OrderedStringStringMap) UnmarshalJSON(b []byte) error {
+	var pairs []jsonPair
+	if err := json.Unmarshal(b, &pairs); err != nil {
+		return err
+	}
+	*o = OrderedStringStringMap{}
+	for _, p := range pairs {
+		o.add(p.Key, p.Value)
+	}
+	return nil
+}
+
+// jsonUnit is the metadata of a single unit inside UnitMetadata
+type jsonUnit struct {
+	Unit     string                 `json:"unit"`
+	Metadata OrderedStringStringMap `json:"metadata"`
+}
+
+// MarshalJSON encodes unit metadata as an array of {"unit": u, "metadata": [...]} objects in order.  Units without
+// metadata in Contents have empty metadata.
+func (u UnitMetadata) MarshalJSON() ([]byte, error) {
+	units := make([]jsonUnit, 0, len(u.Order))
+	for _, unit := range u.Order {
+		ju := jsonUnit{Unit: unit}
+		if metadata := u.Contents[unit]; metadata != nil {
+			ju.Metadata = *metadata
+		}
+		units = append(units, ju)
+	}
+	return json.Marshal(units)
+}
+
+// UnmarshalJSON decodes an array of {"unit": u, "metadata": [...]} objects.  Metadata for the same unit is merged.
+func (u *UnitMetadata) UnmarshalJSON(b []byte) error {
+	var units []jsonUnit
+	if err := json.Unmarshal(b, &units); err != nil {
+		return err
+	}
+	*u = UnitMetadata{}
+	for i := range units {
+		u.Merge(&UnitMetadata{
+			Contents: map[string]*OrderedStringStringMap{units[i].Unit: &units[i].Metadata},
+			Order:    []string{units[i].Unit},
+		})
+	}
+	return nil
+}
+
+// jsonValueUnitPair is the JSON form of ValueUnitPair.  Value is a number, or one of the strings "NaN", "+Inf", or
+// "-Inf" since JSON numbers cannot hold them.
+type jsonValueUnitPair struct {
+	Value json.RawMessage `json:"value"`
+	Unit  string          `json:"unit"`
+}
+
+// MarshalJSON encodes the pair as {"value": v, "unit": u}.  Values that are not finite are encoded as the strings
+// "NaN", "+Inf", or "-Inf".
+func (b ValueUnitPair) MarshalJSON() ([]byte, error) {
+	value := formatValue(b.Value)
+	if math.IsNaN(b.Value) || math.IsInf(b.Value, 0) {
+		value = strconv.Quote(value)
+	}
+	return json.Marshal(jsonValueUnitPair{Value: json.RawMessage(value), Unit: b.Unit})
+}
+
+// UnmarshalJSON decodes {"value": v, "unit": u}, where v is a number or a string holding a number
+func (b *ValueUnitPair) UnmarshalJSON(data []byte) error {
+	var pair jsonValueUnitPair
+	if err := json.Unmarshal(data, &pair); err != nil {
+		return err
+	}
+	var value float64
+	if err := json.Unmarshal(pair.Value, &value); err != nil {
+		var s string
+		if json.Unmarshal(pair.Value, &s) != nil {
+			return err
+		}
+		if value, err = strconv.ParseFloat(s, 64); err != nil {
+			return err
+		}
+	}
+	*b = ValueUnitPair{Value: value, Unit: pair.Unit}
+	return nil
+}
+
+// jsonBenchmarkResult is the JSON form of a BenchmarkResult on its own, with configuration and units inline
+type jsonBenchmarkResult struct {
+	Name          string                  `json:"name"`
+	Iterations    int                     `json:"iterations"`
+	Values        []ValueUnitPair         `json:"values"`
+	Configuration *OrderedStringStringMap `json:"configuration,omitempty"`
+	Units         *UnitMetadata           `json:"units,omitempty"`
+}
+
+// MarshalJSON encodes the result as {"name", "iterations", "values", "configuration", "units"}.  Configuration and
+// units are written inline, and left out if nil.  Results inside a Run are encoded differently.  See Run.MarshalJSON.
+func (b BenchmarkResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonBenchmarkResult{
+		Name:          b.Name,
+		Iterations:    b.Iterations,
+		Values:        b.Values,
+		Configuration: b.Configuration,
+		Units:         b.Units,
+	})
+}
+
+// UnmarshalJSON decodes a result encoded by BenchmarkResult.MarshalJSON
+func (b *BenchmarkResult) UnmarshalJSON(data []byte) error {
+	var result jsonBenchmarkResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	*b = BenchmarkResult{
+		Name:          result.Name,
+		Iterations:    result.Iterations,
+		Values:        result.Values,
+		Configuration: result.Configuration,
+		Units:         result.Units,
+	}
+	return nil
+}
+
+// jsonRun is the JSON form of Run
+type jsonRun struct {
+	Version        int                       `json:"version"`
+	Configurations []*OrderedStringStringMap `json:"configurations"`
+	UnitMetadata   []*UnitMetadata           `json:"unitMetadata"`
+	Results        []jsonRunResult           `json:"results"`
+	Units          *int                      `json:"units,omitempty"`
+	Packages       []jsonPackageSection      `json:"packages"`
+}
+
+// jsonRunResult is the JSON form of a BenchmarkResult inside a Run.  Configuration and Units are indexes into the
+// tables of the run.
+type jsonRunResult struct {
+	Name          string          `json:"name"`
+	Iterations    int             `json:"iterations"`
+	Values        []ValueUnitPair `json:"values"`
+	Configuration *int            `json:"configuration,omitempty"`
+	Units         *int            `json:"units,omitempty"`
+}
+
+// jsonPackageSection is the JSON form of PackageSection
+type jsonPackageSection struct {
+	Package            string        `json:"package"`
+	Status             PackageStatus `json:"status"`
+	ElapsedNanoseconds int64         `json:"elapsedNanoseconds"`
+	Cached             bool          `json:"cached"`
+	ResultsStart       int           `json:"resultsStart"`
+	ResultsEnd         int           `json:"resultsEnd"`
+}
+
+// MarshalJSON encodes the run as a JSON object.  Results that share a Configuration or Units pointer share a single
+// entry of a table, the same way results from a Decoder share them.  The schema is
+//
+//	{
+//	  "version": 1,
+//	  "configurations": [[{"key": "commit", "value": "7cd9055"}]],
+//	  "unitMetadata": [[{"unit": "ns/op", "metadata": [{"key": "better", "value": "lower"}]}]],
+//	  "results": [{
+//	    "name": "BenchmarkDecode-8",
+//	    "iterations": 100,
+//	    "values": [{"value": 154125, "unit": "ns/op"}],
+//	    "configuration": 0,
+//	    "units": 0
+//	  }],
+//	  "units": 0,
+//	  "packages": [{
+//	    "package": "github.com/cep21/a",
+//	    "status": "ok",
+//	    "elapsedNanoseconds": 1200000000,
+//	    "cached": false,
+//	    "resultsStart": 0,
+//	    "resultsEnd": 1
+//	  }]
+//	}
+//
+// "configuration" and "units" of a result, and "units" of the run, are indexes into "configurations" and
+// "unitMetadata".  They are left out if nil.  Values that are not finite are the strings "NaN", "+Inf", or "-Inf".
+// Readers should ignore keys they do not know about.
+func (r Run) MarshalJSON() ([]byte, error) {
+	ret := jsonRun{
+		Version:        JSONVersion,
+		Configurations: []*OrderedStringStringMap{},
+		UnitMetadata:   []*UnitMetadata{},
+		Results:        make([]jsonRunResult, 0, len(r.Results)),
+		Packages:       make([]jsonPackageSection, 0, len(r.Packages)),
+	}
+	configIndexes := make(map[*OrderedStringStringMap]int)
+	unitIndexes := make(map[*UnitMetadata]int)
+	unitIndex := func(u *UnitMetadata) *int {
+		if u == nil {
+			return nil
+		}
+		idx, exists := unitIndexes[u]
+		if !exists {
+			idx = len(ret.UnitMetadata)
+			unitIndexes[u] = idx
+			ret.UnitMetadata = append(ret.UnitMetadata, u)
+		}
+		return &idx
+	}
+	for _, result := range r.Results {
+		jsonResult := jsonRunResult{
+			Name:       result.Name,
+			Iterations: result.Iterations,
+			Values:     result.Values,
+			Units:      unitIndex(result.Units),
+		}
+		if result.Configuration != nil {
+			idx, exists := configIndexes[result.Configuration]
+			if !exists {
+				idx = len(ret.Configurations)
+				configIndexes[result.Configuration] = idx
+				ret.Configurations = append(ret.Configurations, result.Configuration)
+			}
+			jsonResult.Configuration = &idx
+		}
+		ret.Results = append(ret.Results, jsonResult)
+	}
+	ret.Units = unitIndex(r.Units)
+	for _, p := range r.Packages {
+		ret.Packages = append(ret.Packages, jsonPackageSection{
+			Package:            p.Package,
+			Status:             p.Status,
+			ElapsedNanoseconds: int64(p.Elapsed),
+			Cached:             p.Cached,
+			ResultsStart:       p.ResultsStart,
+			ResultsEnd:         p.ResultsEnd,
+		})
+	}
+	return json.Marshal(ret)
+}
+
+// UnmarshalJSON decodes a run encoded by Run.MarshalJSON.  Results that refer to the same table entry share the same
+// Configuration or Units pointer.  Returns ErrJSONVersion for a newer schema version and ErrJSONReference for an
+// index that is not inside its table, or a package section whose range of results is not inside the results.
+func (r *Run) UnmarshalJSON(data []byte) error {
+	var run jsonRun
+	if err := json.Unmarshal(data, &run); err != nil {
+		return err
+	}
+	if run.Version > JSONVersion {
+		return ErrJSONVersion
+	}
+	ret := Run{}
+	for _, result := range run.Results {
+		b := BenchmarkResult{
+			Name:       result.Name,
+			Iterations: result.Iterations,
+			Values:     result.Values,
+		}
+		if result.Configuration != nil {
+			if *result.Configuration < 0 || *result.Configuration >= len(run.Configurations) {
+				return ErrJSONReference
+			}
+			b.Configuration = run.Configurations[*result.Configuration]
+		}
+		units, err := run.unitMetadata(result.Units)
+		if err != nil {
+			return err
+		}
+		b.Units = units
+		ret.Results = append(ret.Results, b)
+	}
+	units, err := run.unitMetadata(run.Units)
+	if err != nil {
+		return err
+	}
+	ret.Units = units
+	for _, p := range run.Packages {
+		if p.ResultsStart < 0 || p.ResultsStart > p.ResultsEnd || p.ResultsEnd > len(ret.Results) {
+			return ErrJSONReference
+		}
+		ret.Packages = append(ret.Packages, PackageSection{
+			Package:      p.Package,
+			Status:       p.Status,
+			Elapsed:      time.Duration(p.ElapsedNanoseconds),
+			Cached:       p.Cached,
+			ResultsStart: p.ResultsStart,
+			ResultsEnd:   p.ResultsEnd,
+		})
+	}
+	*r = ret
+	return nil
+}
+
+// unitMetadata returns the entry of the unit metadata table at idx, which is nil for results without units
+func (j *jsonRun) unitMetadata(idx *int) (*UnitMetadata, error) {
+	if idx == nil {
+		return nil, nil
+	}
+	if *idx < 0 || *idx >= len(j.UnitMetadata) {
+		return nil, ErrJSONReference
+	}
+	return j.UnitMetadata[*idx], nil
+}
@@ -0,0 +1,151 @@
+package benchparse
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderedStringStringMap_MarshalJSON(t *testing.T) {
+	m := &OrderedStringStringMap{}
+	m.add("commit", "7cd9055")
+	m.add("cpu", "amd")
+	m.add("a", "")
+	b, err := json.Marshal(m)
+	require.NoError(t, err)
+	require.Equal(t, `[{"key":"commit","value":"7cd9055"},{"key":"cpu","value":"amd"},{"key":"a","value":""}]`, string(b))
+
+	var decoded OrderedStringStringMap
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	require.Equal(t, *m, decoded)
+
+	require.Error(t, json.Unmarshal([]byte(`{"commit":"7cd9055"}`), &decoded))
+}
+
+func TestUnitMetadata_MarshalJSON(t *testing.T) {
+	u := UnitMetadata{
+		Order: []string{"ns/op", "B/op", "allocs/op"},
+		Contents: map[string]*OrderedStringStringMap{
+			"ns/op": {Order: []string{"better"}, Contents: map[string]string{"better": "lower"}},
+			"B/op":  nil,
+		},
+	}
+	b, err := json.Marshal(u)
+	require.NoError(t, err)
+	require.Equal(t, `[{"unit":"ns/op","metadata":[{"key":"better","value":"lower"}]},{"unit":"B/op","metadata":[]},{"unit":"allocs/op","metadata":[]}]`, string(b))
+}
+
+func TestValueUnitPair_MarshalJSON(t *testing.T) {
+	verifyJSON := func(v ValueUnitPair, expected string) func(t *testing.T) {
+		return func(t *testing.T) {
+			b, err := json.Marshal(v)
+			require.NoError(t, err)
+			require.Equal(t, expected, string(b))
+			var decoded ValueUnitPair
+			require.NoError(t, json.Unmarshal(b, &decoded))
+			require.Equal(t, v.Unit, decoded.Unit)
+			if math.IsNaN(v.Value) {
+				require.True(t, math.IsNaN(decoded.Value))
+				return
+			}
+			require.Equal(t, v.Value, decoded.Value)
+		}
+	}
+	t.Run("case=int", verifyJSON(ValueUnitPair{Value: 154125, Unit: "ns/op"}, `{"value":154125,"unit":"ns/op"}`))
+	t.Run("case=float", verifyJSON(ValueUnitPair{Value: 64.88, Unit: "MB/s"}, `{"value":64.88,"unit":"MB/s"}`))
+	t.Run("case=nan", verifyJSON(ValueUnitPair{Value: math.NaN(), Unit: "x"}, `{"value":"NaN","unit":"x"}`))
+	t.Run("case=inf", verifyJSON(ValueUnitPair{Value: math.Inf(1), Unit: "x"}, `{"value":"+Inf","unit":"x"}`))
+	t.Run("case=neginf", verifyJSON(ValueUnitPair{Value: math.Inf(-1), Unit: "x"}, `{"value":"-Inf","unit":"x"}`))
+
+	var decoded ValueUnitPair
+	require.Error(t, json.Unmarshal([]byte(`{"value":"fast","unit":"x"}`), &decoded))
+	require.Error(t, json.Unmarshal([]byte(`{"value":true,"unit":"x"}`), &decoded))
+}
+
+func TestBenchmarkResult_MarshalJSON(t *testing.T) {
+	run, err := Decoder{}.Decode(strings.NewReader(`commit: 7cd9055
+Unit ns/op better=lower
+BenchmarkBob-8 100 10 ns/op 3 B/op
+`))
+	require.NoError(t, err)
+	b, err := json.Marshal(run.Results[0])
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+  "name": "BenchmarkBob-8",
+  "iterations": 100,
+  "values": [{"value": 10, "unit": "ns/op"}, {"value": 3, "unit": "B/op"}],
+  "configuration": [{"key": "commit", "value": "7cd9055"}],
+  "units": [{"unit": "ns/op", "metadata": [{"key": "better", "value": "lower"}]}]
+}`, string(b))
+	var decoded BenchmarkResult
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	require.Equal(t, run.Results[0], decoded)
+
+	b, err = json.Marshal(BenchmarkResult{Name: "BenchmarkBob", Iterations: 1, Values: []ValueUnitPair{{Value: 1, Unit: "ns/op"}}})
+	require.NoError(t, err)
+	require.Equal(t, `{"name":"BenchmarkBob","iterations":1,"values":[{"value":1,"unit":"ns/op"}]}`, string(b))
+}
+
+func TestRun_MarshalJSON(t *testing.T) {
+	run, err := Decoder{}.Decode(strings.NewReader(`commit: 7cd9055
+BenchmarkBob 100 10 ns/op
+BenchmarkJack 100 20 ns/op
+Unit ns/op better=lower
+commit: 7cd9056
+BenchmarkBob 100 11 ns/op
+Unit B/op better=lower
+ok  	github.com/cep21/a	1.2s
+`))
+	require.NoError(t, err)
+	b, err := json.Marshal(run)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+  "version": 1,
+  "configurations": [
+    [{"key": "commit", "value": "7cd9055"}],
+    [{"key": "commit", "value": "7cd9056"}]
+  ],
+  "unitMetadata": [
+    [{"unit": "ns/op", "metadata": [{"key": "better", "value": "lower"}]}],
+    [{"unit": "ns/op", "metadata": [{"key": "better", "value": "lower"}]}, {"unit": "B/op", "metadata": [{"key": "better", "value": "lower"}]}]
+  ],
+  "results": [
+    {"name": "BenchmarkBob", "iterations": 100, "values": [{"value": 10, "unit": "ns/op"}], "configuration": 0},
+    {"name": "BenchmarkJack", "iterations": 100, "values": [{"value": 20, "unit": "ns/op"}], "configuration": 0},
+    {"name": "BenchmarkBob", "iterations": 100, "values": [{"value": 11, "unit": "ns/op"}], "configuration": 1, "units": 0}
+  ],
+  "units": 1,
+  "packages": [
+    {"package": "github.com/cep21/a", "status": "ok", "elapsedNanoseconds": 1200000000, "cached": false, "resultsStart": 0, "resultsEnd": 3}
+  ]
+}`, string(b))
+
+	var decoded Run
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	require.Equal(t, *run, decoded)
+	require.True(t, decoded.Results[0].Configuration == decoded.Results[1].Configuration)
+	require.False(t, decoded.Results[1].Configuration == decoded.Results[2].Configuration)
+	require.Equal(t, 1200*time.Millisecond, decoded.Packages[0].Elapsed)
+}
+
+func TestRun_UnmarshalJSON(t *testing.T) {
+	var run Run
+	require.NoError(t, json.Unmarshal([]byte(`{"results": [{"name": "BenchmarkBob", "iterations": 1, "values": []}], "extra": true}`), &run))
+	require.Len(t, run.Results, 1)
+	require.Nil(t, run.Results[0].Configuration)
+	require.Nil(t, run.Units)
+
+	require.Equal(t, ErrJSONVersion, json.Unmarshal([]byte(`{"version": 2}`), &run))
+	require.Equal(t, ErrJSONReference, json.Unmarshal([]byte(`{"results": [{"configuration": 0}]}`), &run))
+	require.Equal(t, ErrJSONReference, json.Unmarshal([]byte(`{"results": [{"units": -1}], "unitMetadata": [[]]}`), &run))
+	require.Equal(t, ErrJSONReference, json.Unmarshal([]byte(`{"units": 1}`), &run))
+	results := `"results": [{"name": "BenchmarkA", "iterations": 1, "values": [{"value": 1, "unit": "ns/op"}]}]`
+	require.Equal(t, ErrJSONReference, json.Unmarshal([]byte(`{`+results+`, "packages": [{"resultsStart": 0, "resultsEnd": 5}]}`), &run))
+	require.Equal(t, ErrJSONReference, json.Unmarshal([]byte(`{`+results+`, "packages": [{"resultsStart": -1, "resultsEnd": 1}]}`), &run))
+	require.Equal(t, ErrJSONReference, json.Unmarshal([]byte(`{`+results+`, "packages": [{"resultsStart": 1, "resultsEnd": 0}]}`), &run))
+	require.NoError(t, json.Unmarshal([]byte(`{`+results+`, "packages": [{"resultsStart": 0, "resultsEnd": 1}]}`), &run))
+}
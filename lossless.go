@@ -0,0 +1,189 @@
+package benchparse
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// rawRun is the original text of a stream decoded by a lossless Decoder that is not part of any result
+type rawRun struct {
+	// resultCount is the number of results in the stream
+	resultCount int
+	// trailer is every line after the last result
+	trailer string
+	// units is a copy of the unit metadata at the end of the stream
+	units *UnitMetadata
+}
+
+// rawResult is the original text of a result decoded by a lossless Decoder
+type rawResult struct {
+	// run is the stream this result is part of and index is the position of the result in that stream
+	run   *rawRun
+	index int
+	// preamble is every line between the previous result and this one, with line endings
+	preamble string
+	// line is the result line, without the line ending in terminator.  terminator is empty if the line ended the
+	// input.
+	line       string
+	terminator string
+	// state is the decoder's state after this result
+	state rawState
+}
+
+// rawState is a copy of the configuration and unit metadata a lossless Decoder had at some line, used to detect if
+// a result was modified after it was decoded
+type rawState struct {
+	configuration *OrderedStringStringMap
+	units         *UnitMetadata
+}
+
+// matches returns true if configuration and units are the same as when the state was copied
+func (r rawState) matches(configuration *OrderedStringStringMap, units *UnitMetadata) bool {
+	return r.configuration.equal(configuration) && r.units.equal(units)
+}
+
+// rawTracker remembers the original text of a stream as a lossless Decoder decodes it
+type rawTracker struct {
+	run *rawRun
+	// line is the line being decoded, with its line ending.  It is empty once a result takes it.
+	line string
+	// pending is every line since the previous result
+	pending strings.Builder
+	// lastConfiguration and lastUnits are the objects that state copies.  Decoder shares both objects between results
+	// until they change, so copies are shared the same way.
+	lastConfiguration *OrderedStringStringMap
+	lastUnits         *UnitMetadata
+	state             rawState
+}
+
+func newRawTracker() *rawTracker {
+	return &rawTracker{
+		run: &rawRun{},
+	}
+}
+
+// startLine starts decoding a line of input that includes its line ending
+func (t *rawTracker) startLine(line string) {
+	t.line = line
+}
+
+// endLine finishes decoding a line.  Lines that were not a result are added to the text before the next result.
+func (t *rawTracker) endLine() {
+	t.pending.WriteString(t.line)
+	t.line = ""
+}
+
+// result takes the original text of the current line, which is the result r, and attaches it to r
+func (t *rawTracker) result(r *BenchmarkResult, line string) {
+	t.updateState(r.encodedConfiguration(), r.Units)
+	r.raw = &rawResult{
+		run:        t.run,
+		index:      t.run.resultCount,
+		preamble:   t.pending.String(),
+		line:       line,
+		terminator: t.line[len(line):],
+		state:      t.state,
+	}
+	t.run.resultCount++
+	t.pending.Reset()
+	t.line = ""
+}
+
+// finish records the lines after the last result and the final unit metadata of the stream.  It returns nil if t is
+// nil, which is the case for decoders that are not lossless.
+func (t *rawTracker) finish(units *UnitMetadata) *rawRun {
+	if t == nil {
+		return nil
+	}
+	t.run.trailer = t.pending.String()
+	t.run.units = units.Clone()
+	return t.run
+}
+
+func (t *rawTracker) updateState(configuration *OrderedStringStringMap, units *UnitMetadata) {
+	if configuration != t.lastConfiguration || t.state.configuration == nil {
+		t.lastConfiguration = configuration
+		t.state.configuration = configuration.Clone()
+	}
+	if units != t.lastUnits {
+		t.lastUnits = units
+		t.state.units = units.Clone()
+	}
+}
+
+// follows returns true if text a lossless Decoder decoded after the result at index-1 of run can be written after
+// previous, the last result an Encoder wrote.  configuration and units are the ones the Encoder last wrote.
+func follows(run *rawRun, index int, previous *rawResult, written int, configuration *OrderedStringStringMap, units *UnitMetadata) bool {
+	if index == 0 {
+		return written == 0
+	}
+	return previous != nil && previous.run == run && previous.index+1 == index &&
+		previous.state.matches(configuration, units)
+}
+
+// unchangedLine returns true if r has the same name and values it was decoded with
+func (r *rawResult) unchangedLine(b BenchmarkResult) bool {
+	original, err := (&benchmarkResultDecoder{}).decode(r.line)
+	if err != nil || original.Name != b.Name || original.Iterations != b.Iterations ||
+		len(original.Values) != len(b.Values) {
+		return false
+	}
+	for i := range original.Values {
+		if !sameValue(original.Values[i], b.Values[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// fields returns the fields used to encode b: the name, iterations, and each value and unit.  Fields that did not
+// change since b was decoded keep their original spelling.
+func (r *rawResult) fields(b BenchmarkResult) []string {
+	ret := make([]string, 0, len(b.Values)*2+2)
+	ret = append(ret, b.Name, strconv.Itoa(b.Iterations))
+	for _, v := range b.Values {
+		ret = append(ret, formatValue(v.Value), v.Unit)
+	}
+	if r == nil {
+		return ret
+	}
+	original, err := (&benchmarkResultDecoder{}).decode(r.line)
+	if err != nil {
+		return ret
+	}
+	originalFields := strings.Fields(r.line)
+	if original.Iterations == b.Iterations {
+		ret[1] = originalFields[1]
+	}
+	for i, v := range b.Values {
+		if i < len(original.Values) && sameValue(original.Values[i], v) {
+			ret[i*2+2] = originalFields[i*2+2]
+		}
+	}
+	return ret
+}
+
+// sameValue is true if both pairs are equal, treating NaN values as equal to each other
+func sameValue(a ValueUnitPair, b ValueUnitPair) bool {
+	return a.Unit == b.Unit && (a.Value == b.Value || a.Value != a.Value && b.Value != b.Value)
+}
+
+// scanRawLines is a bufio.SplitFunc like bufio.ScanLines that keeps the line ending of each line
+func scanRawLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1, data[:i+1], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// trimLineEnding removes the line ending bufio.ScanLines would remove from line
+func trimLineEnding(line string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+}
@@ -0,0 +1,139 @@
+package benchparse
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func losslessDecode(t *testing.T, in string) *Run {
+	d := Decoder{Lossless: true}
+	run, err := d.Decode(strings.NewReader(in))
+	require.NoError(t, err)
+	return run
+}
+
+func losslessEncode(t *testing.T, e Encoder, run *Run) string {
+	var buf bytes.Buffer
+	require.NoError(t, e.Encode(&buf, run))
+	return buf.String()
+}
+
+func TestEncoder_Encode_lossless(t *testing.T) {
+	exactEncode := func(s string) func(t *testing.T) {
+		return func(t *testing.T) {
+			run := losslessDecode(t, s)
+			require.Equal(t, s, losslessEncode(t, Encoder{}, run))
+			require.Equal(t, s, losslessEncode(t, Encoder{Align: true}, run))
+		}
+	}
+	t.Run("case=empty", exactEncode(""))
+	t.Run("case=readme", exactEncode(readmeExample))
+	t.Run("case=noisy", exactEncode(noisyExample))
+	t.Run("case=gotest", exactEncode(goTestExample))
+	t.Run("case=numbers", exactEncode(`BenchmarkBob 0100 1.2e+06 ns/op 7.250 MB/s 40418.0 B/op
+`))
+	t.Run("case=whitespace", exactEncode("commit:   7cd9055  \n\n  BenchmarkBob  \t 100\t10 ns/op   \n\t\n"))
+	t.Run("case=crlf", exactEncode("commit: 7cd9055\r\nBenchmarkBob 100 10 ns/op\r\nPASS\r\n"))
+	t.Run("case=nonewline", exactEncode("commit: 7cd9055\nBenchmarkBob 100 10 ns/op"))
+	t.Run("case=trailer", exactEncode("BenchmarkBob 100 10 ns/op\nUnit ns/op better=lower\nok  \tgithub.com/cep21/a\t1.2s"))
+	t.Run("case=noresults", exactEncode("goos: linux\nUnit ns/op better=lower\nPASS\n"))
+	t.Run("case=removekeys", exactEncode(`commit: 7cd9055
+cpu: amd
+BenchmarkBob 100 10 ns/op
+cpu:
+BenchmarkBob 100 10 ns/op
+`))
+}
+
+func TestEncoder_Encode_losslessModified(t *testing.T) {
+	in := `commit: 7cd9055
+
+BenchmarkBob    100   1.2e+06 ns/op   7.250 MB/s
+BenchmarkJack   100   10 ns/op
+PASS
+`
+	t.Run("case=value", func(t *testing.T) {
+		run := losslessDecode(t, in)
+		run.Results[0].Values[1].Value = 8
+		require.Equal(t, `commit: 7cd9055
+
+BenchmarkBob 100 1.2e+06 ns/op 8 MB/s
+BenchmarkJack   100   10 ns/op
+PASS
+`, losslessEncode(t, Encoder{}, run))
+	})
+	t.Run("case=name", func(t *testing.T) {
+		run := losslessDecode(t, in)
+		run.Results[1].Name = "BenchmarkJill"
+		require.Equal(t, `commit: 7cd9055
+
+BenchmarkBob    100   1.2e+06 ns/op   7.250 MB/s
+BenchmarkJill 100 10 ns/op
+PASS
+`, losslessEncode(t, Encoder{}, run))
+	})
+	t.Run("case=configuration", func(t *testing.T) {
+		run := losslessDecode(t, in)
+		run.Results[1].Configuration = &OrderedStringStringMap{
+			Contents: map[string]string{"commit": "7cd9056"},
+			Order:    []string{"commit"},
+		}
+		out := losslessEncode(t, Encoder{}, run)
+		require.Equal(t, `commit: 7cd9055
+
+BenchmarkBob    100   1.2e+06 ns/op   7.250 MB/s
+commit: 7cd9056
+BenchmarkJack   100   10 ns/op
+`, out)
+		decoded, err := Decoder{}.Decode(strings.NewReader(out))
+		require.NoError(t, err)
+		require.Equal(t, "7cd9056", decoded.Results[1].Configuration.Contents["commit"])
+	})
+	t.Run("case=inplace", func(t *testing.T) {
+		run := losslessDecode(t, in)
+		run.Results[0].Configuration.add("commit", "7cd9056")
+		require.Equal(t, `commit: 7cd9056
+BenchmarkBob    100   1.2e+06 ns/op   7.250 MB/s
+BenchmarkJack   100   10 ns/op
+`, losslessEncode(t, Encoder{}, run))
+	})
+	t.Run("case=reorder", func(t *testing.T) {
+		run := losslessDecode(t, in)
+		run.Results[0], run.Results[1] = run.Results[1], run.Results[0]
+		require.Equal(t, `commit: 7cd9055
+BenchmarkJack   100   10 ns/op
+BenchmarkBob    100   1.2e+06 ns/op   7.250 MB/s
+`, losslessEncode(t, Encoder{}, run))
+	})
+	t.Run("case=units", func(t *testing.T) {
+		run := losslessDecode(t, in)
+		run.Units = &UnitMetadata{}
+		run.Units.add("ns/op", &OrderedStringStringMap{
+			Contents: map[string]string{"better": "lower"},
+			Order:    []string{"better"},
+		})
+		require.Equal(t, `commit: 7cd9055
+
+BenchmarkBob    100   1.2e+06 ns/op   7.250 MB/s
+BenchmarkJack   100   10 ns/op
+Unit ns/op better=lower
+`, losslessEncode(t, Encoder{}, run))
+	})
+}
+
+func TestDecoder_Decode_lossless(t *testing.T) {
+	d := Decoder{}
+	expected, err := d.Decode(strings.NewReader(goTestExample))
+	require.NoError(t, err)
+	run := losslessDecode(t, goTestExample)
+	require.Len(t, run.Results, len(expected.Results))
+	for i := range expected.Results {
+		require.Equal(t, expected.Results[i].Name, run.Results[i].Name)
+		require.Equal(t, expected.Results[i].Values, run.Results[i].Values)
+		require.Equal(t, expected.Results[i].Configuration, run.Results[i].Configuration)
+	}
+	require.Equal(t, expected.Packages, run.Packages)
+}
@@ -13,30 +13,35 @@ type OrderedStringStringMap struct {
 }
 
 // valuesToTransition returns the OrderedStringStringMap object that is required to transition from the current
-// key/value pairs to the newState of key/value pairs.  Not all transitions are possible.  It does a best guess
-// ordering.
+// key/value pairs to the newState of key/value pairs.  Keys that exist in the current state but not in newState are
+// returned first, with an empty value, since the spec uses a configuration line with an empty value to clear a key.
+// Not all transitions are possible: keys with an empty value in newState cannot be told apart from removed keys.  It
+// does a best guess ordering.
 func (o *OrderedStringStringMap) valuesToTransition(newState *OrderedStringStringMap) *OrderedStringStringMap {
+	ret := &OrderedStringStringMap{}
 	if o == newState {
-		return &OrderedStringStringMap{}
-	}
-	if o == nil || len(o.Contents) == 0 {
-		return newState
+		return ret
 	}
-	if newState == nil {
-		return o
+	if o != nil {
+		for _, k := range o.Order {
+			if _, exists := newState.lookup(k); !exists {
+				ret.add(k, "")
+			}
+		}
 	}
-	ret := &OrderedStringStringMap{}
-	for _, k := range newState.Order {
-		v := newState.Contents[k]
-		if !o.exists(k, v) {
-			ret.add(k, v)
+	if newState != nil {
+		for _, k := range newState.Order {
+			v := newState.Contents[k]
+			if !o.exists(k, v) {
+				ret.add(k, v)
+			}
 		}
 	}
 	return ret
 }
 
-// clone makes a deep copy of this object
-func (o *OrderedStringStringMap) clone() *OrderedStringStringMap {
+// Clone makes a deep copy of this object.  The Clone of nil is nil.
+func (o *OrderedStringStringMap) Clone() *OrderedStringStringMap {
 	if o == nil {
 		return nil
 	}
@@ -47,9 +52,39 @@ func (o *OrderedStringStringMap) clone() *OrderedStringStringMap {
 	return ret
 }
 
-// exists returns true if this key/value pair exists in the map
+// equal returns true if both maps have the same pairs in the same order.  A nil map is equal to an empty map.
+func (o *OrderedStringStringMap) equal(other *OrderedStringStringMap) bool {
+	var order, otherOrder []string
+	if o != nil {
+		order = o.Order
+	}
+	if other != nil {
+		otherOrder = other.Order
+	}
+	if len(order) != len(otherOrder) {
+		return false
+	}
+	for i, k := range order {
+		if k != otherOrder[i] || o.Contents[k] != other.Contents[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// exists returns true if this key/value pair exists in the map.  A nil map has no pairs.
 func (o *OrderedStringStringMap) exists(k string, v string) bool {
-	return o.Contents[k] == v
+	current, exists := o.lookup(k)
+	return exists && current == v
+}
+
+// lookup returns the value of k and if it exists.  A nil map has no keys.
+func (o *OrderedStringStringMap) lookup(k string) (string, bool) {
+	if o == nil {
+		return "", false
+	}
+	v, exists := o.Contents[k]
+	return v, exists
 }
 
 // add a key to this map at the ordering "last"
@@ -0,0 +1,11 @@
+/*
+Package report renders decoded benchmark runs, comparisons of two runs, and pivot tables, as tables meant for people to
+read, such as plain text for a terminal, a GitHub-flavored Markdown table for a pull request description, or a
+self-contained HTML page, or as JUnit XML for CI systems.
+
+Each benchmark function gets its own table.  The columns of a table are the key=value pairs of the benchmark names, and
+configuration keys, whose values vary between the rows of the table.  Pairs every row shares are written once above
+the table instead.  Values are shown in human-scaled units, like "154.1 µs/op" instead of "154125 ns/op", and
+significant improvements and regressions of a comparison are highlighted.
+*/
+package report
@@ -0,0 +1,43 @@
+package report_test
+
+import (
+	"os"
+	"strings"
+
+	"github.com/cep21/benchparse"
+	"github.com/cep21/benchparse/analysis"
+	"github.com/cep21/benchparse/report"
+)
+
+func ExampleMarkdownReporter_ReportComparison() {
+	d := benchparse.Decoder{}
+	old, err := d.Decode(strings.NewReader(`BenchmarkDecode/size=1e4-8 100 154125 ns/op
+BenchmarkDecode/size=1e4-8 100 155125 ns/op
+BenchmarkDecode/size=1e4-8 100 153125 ns/op
+BenchmarkDecode/size=1e4-8 100 154525 ns/op
+BenchmarkDecode/size=1e4-8 100 154225 ns/op
+`))
+	if err != nil {
+		panic(err)
+	}
+	new, err := d.Decode(strings.NewReader(`BenchmarkDecode/size=1e4-8 100 144125 ns/op
+BenchmarkDecode/size=1e4-8 100 145125 ns/op
+BenchmarkDecode/size=1e4-8 100 143125 ns/op
+BenchmarkDecode/size=1e4-8 100 144525 ns/op
+BenchmarkDecode/size=1e4-8 100 144225 ns/op
+`))
+	if err != nil {
+		panic(err)
+	}
+	r := report.MarkdownReporter{}
+	if err := r.ReportComparison(os.Stdout, analysis.Compare(old, new)); err != nil {
+		panic(err)
+	}
+	// Output: ### Decode (ns/op)
+	//
+	// size=1e4
+	//
+	// | old | new | delta |  |
+	// | --: | --: | --: | --: |
+	// | 154.2 µs/op ± 1% | 144.2 µs/op ± 1% | **-6.48%** :green_circle: | p=0.008 n=5+5 |
+}
@@ -0,0 +1,76 @@
+package report
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/cep21/benchparse"
+	"github.com/cep21/benchparse/analysis"
+)
+
+// significantDigits is how many significant digits formatValue keeps
+const significantDigits = 4
+
+// formatValue formats value of unit scaled to a human readable prefix, like "154.1 µs/op" for 154125 ns/op
+func formatValue(value float64, unit string) string {
+	scaled, scaledUnit := benchparse.ParseUnit(unit).Scale(value)
+	return formatNumber(scaled) + " " + scaledUnit.String()
+}
+
+// formatNumber formats v with significantDigits significant digits, but never drops digits before the decimal point
+// or uses an exponent
+func formatNumber(v float64) string {
+	if v == 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	decimals := significantDigits - 1 - int(math.Floor(math.Log10(math.Abs(v))))
+	if decimals < 0 {
+		decimals = 0
+	}
+	return strconv.FormatFloat(v, 'f', decimals, 64)
+}
+
+// formatSummary formats the median of s, followed by the relative half width of its confidence interval if there is
+// one, like "154.1 µs/op ± 2%"
+func formatSummary(s analysis.Summary) string {
+	ret := formatValue(s.Median, s.Unit)
+	if math.IsNaN(s.CILow) || math.IsNaN(s.CIHigh) || s.Median == 0 {
+		return ret
+	}
+	spread := (s.CIHigh - s.CILow) / 2 / math.Abs(s.Median) * 100
+	return ret + " ± " + strconv.FormatFloat(spread, 'f', 0, 64) + "%"
+}
+
+// formatTest formats the p-value and sample sizes of a comparison, like "p=0.008 n=5+5"
+func formatTest(row analysis.ComparisonRow) string {
+	n := " n=" + strconv.Itoa(row.Old.N) + "+" + strconv.Itoa(row.New.N)
+	if math.IsNaN(row.PValue) {
+		return row.Verdict.String() + n
+	}
+	return "p=" + strconv.FormatFloat(row.PValue, 'f', 3, 64) + n
+}
+
+// higherIsBetter returns true if larger values of unit are improvements.  The "better" unit metadata decides if it
+// exists.  Otherwise rates, like MB/s, are better higher and everything else, like ns/op, is better lower.
+func higherIsBetter(unit string, units *benchparse.UnitMetadata) bool {
+	if better, exists := units.Lookup(unit, "better"); exists {
+		return better == "higher"
+	}
+	denominator := benchparse.ParseUnit(unit).Denominator
+	return denominator == "s" || denominator == "sec"
+}
+
+// changeOf returns if row is a significant improvement or regression
+func changeOf(row analysis.ComparisonRow) change {
+	if !row.Significant || row.Delta == 0 {
+		return noChange
+	}
+	var units *benchparse.UnitMetadata
+	if len(row.New.Group.Results) > 0 {
+		units = row.New.Group.Results[0].Units
+	}
+	if (row.Delta > 0) == higherIsBetter(row.Unit, units) {
+		return improvement
+	}
+	return regression
+}
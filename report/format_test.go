@@ -0,0 +1,32 @@
+package report
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/cep21/benchparse"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatValue(t *testing.T) {
+	require.Equal(t, "154.1 µs/op", formatValue(154125, "ns/op"))
+	require.Equal(t, "13.00 s/op", formatValue(13000839000, "ns/op"))
+	require.Equal(t, "64.88 MB/s", formatValue(64.88, "MB/s"))
+	require.Equal(t, "1.000 KiB/op", formatValue(1, "KiB/op"))
+	require.Equal(t, "12345 allocs/op", formatValue(12345, "allocs/op"))
+	require.Equal(t, "0 ns/op", formatValue(0, "ns/op"))
+	require.Equal(t, "-2.000 µs/op", formatValue(-2000, "ns/op"))
+	require.Equal(t, "NaN x", formatValue(math.NaN(), "x"))
+}
+
+func TestHigherIsBetter(t *testing.T) {
+	require.False(t, higherIsBetter("ns/op", nil))
+	require.False(t, higherIsBetter("B/op", nil))
+	require.True(t, higherIsBetter("MB/s", nil))
+	require.True(t, higherIsBetter("ops/sec", nil))
+	run, err := benchparse.Decoder{}.Decode(strings.NewReader("Unit hits/op better=higher\nUnit MB/s better=lower\n"))
+	require.NoError(t, err)
+	require.True(t, higherIsBetter("hits/op", run.Units))
+	require.False(t, higherIsBetter("MB/s", run.Units))
+}
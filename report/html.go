@@ -0,0 +1,103 @@
+package report
+
+import (
+	"html"
+	"io"
+	"strings"
+
+	"github.com/cep21/benchparse"
+	"github.com/cep21/benchparse/analysis"
+)
+
+// HTMLReporter renders runs and comparisons as a self-contained HTML page, with the styles inside the page.
+// Significant improvements have a green background and significant regressions a red one.
+type HTMLReporter struct {
+	// Title of the page.  It defaults to "Benchmarks".
+	Title string
+}
+
+// htmlStyle is the style sheet of every page
+const htmlStyle = `body { font-family: sans-serif; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #d0d7de; padding: 4px 8px; }
+th { background: #f6f8fa; }
+td.number { text-align: right; font-family: monospace; white-space: nowrap; }
+td.improvement { background: #dafbe1; }
+td.regression { background: #ffebe9; }
+`
+
+// ReportRun writes a page with a table of the median of each unit for every benchmark of run.  Configuration every
+// result shares is listed once before the tables.
+func (h *HTMLReporter) ReportRun(w io.Writer, run *benchparse.Run) error {
+	shared, tables := runTables(run)
+	return h.write(w, shared, tables)
+}
+
+// ReportComparison writes a page with a table comparing the old and new median of each benchmark and unit of c
+func (h *HTMLReporter) ReportComparison(w io.Writer, c *analysis.Comparison) error {
+	return h.write(w, nil, comparisonTables(c))
+}
+
+// ReportTable writes a page with a pivot table, with a column for each row key and then for each column of t
+func (h *HTMLReporter) ReportTable(w io.Writer, t *analysis.Table) error {
+	return h.write(w, nil, []table{pivotTable(t)})
+}
+
+func (h *HTMLReporter) write(w io.Writer, shared []string, tables []table) error {
+	title := h.Title
+	if title == "" {
+		title = "Benchmarks"
+	}
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	b.WriteString("<title>" + html.EscapeString(title) + "</title>\n")
+	b.WriteString("<style>\n" + htmlStyle + "</style>\n</head>\n<body>\n")
+	b.WriteString("<h1>" + html.EscapeString(title) + "</h1>\n")
+	if len(shared) > 0 {
+		b.WriteString("<ul>\n")
+		for _, s := range shared {
+			b.WriteString("<li>" + html.EscapeString(s) + "</li>\n")
+		}
+		b.WriteString("</ul>\n")
+	}
+	for _, t := range tables {
+		b.WriteString("<h3>" + html.EscapeString(t.title) + "</h3>\n")
+		if len(t.shared) > 0 {
+			b.WriteString("<p>" + html.EscapeString(strings.Join(t.shared, " ")) + "</p>\n")
+		}
+		b.WriteString("<table>\n<thead>\n<tr>")
+		for _, header := range t.headers {
+			b.WriteString("<th>" + html.EscapeString(header) + "</th>")
+		}
+		b.WriteString("</tr>\n</thead>\n<tbody>\n")
+		for _, row := range t.rows {
+			b.WriteString("<tr>")
+			for j, c := range row {
+				b.WriteString(htmlCell(c, j >= t.keyColumns))
+			}
+			b.WriteString("</tr>\n")
+		}
+		b.WriteString("</tbody>\n</table>\n")
+	}
+	b.WriteString("</body>\n</html>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// htmlCell formats c as a td element.  Numbers are right aligned and improvements and regressions highlighted.
+func htmlCell(c cell, number bool) string {
+	var classes []string
+	if number {
+		classes = append(classes, "number")
+	}
+	switch c.change {
+	case improvement:
+		classes = append(classes, "improvement")
+	case regression:
+		classes = append(classes, "regression")
+	}
+	if len(classes) == 0 {
+		return "<td>" + html.EscapeString(c.text) + "</td>"
+	}
+	return `<td class="` + strings.Join(classes, " ") + `">` + html.EscapeString(c.text) + "</td>"
+}
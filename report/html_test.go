@@ -0,0 +1,75 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cep21/benchparse/analysis"
+	"github.com/cep21/benchparse/internal/benchtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTMLReporter_ReportRun(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, (&HTMLReporter{Title: "<Nightly>"}).ReportRun(&buf, benchtest.DecodeRun(t, `commit: 7cd9055
+BenchmarkEncode/fast-8 2000 1.5 ns/op
+BenchmarkEncode/slow<b>-8 2000 2500 ns/op
+`)))
+	require.Equal(t, `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>&lt;Nightly&gt;</title>
+<style>
+`+htmlStyle+`</style>
+</head>
+<body>
+<h1>&lt;Nightly&gt;</h1>
+<ul>
+<li>commit: 7cd9055</li>
+</ul>
+<h3>Encode</h3>
+<table>
+<thead>
+<tr><th>name</th><th>ns/op</th></tr>
+</thead>
+<tbody>
+<tr><td>fast</td><td class="number">1.500 ns/op</td></tr>
+<tr><td>slow&lt;b&gt;</td><td class="number">2.500 µs/op</td></tr>
+</tbody>
+</table>
+</body>
+</html>
+`, buf.String())
+}
+
+func TestHTMLReporter_ReportComparison(t *testing.T) {
+	c := analysis.Compare(benchtest.DecodeRun(t, oldExample), benchtest.DecodeRun(t, newExample))
+	var buf bytes.Buffer
+	require.NoError(t, (&HTMLReporter{}).ReportComparison(&buf, c))
+	out := buf.String()
+	require.Contains(t, out, "<title>Benchmarks</title>")
+	require.Contains(t, out, "<h3>Decode (ns/op)</h3>\n<p>level=speed</p>\n")
+	require.Contains(t, out, `<tr><td>digits</td><td>1e4</td><td class="number">154.2 µs/op ± 1%</td><td class="number">164.2 µs/op ± 1%</td><td class="number regression">+6.48%</td><td class="number">p=0.008 n=5+5</td></tr>`)
+	require.Contains(t, out, `<tr><td>twain</td><td>1e6</td><td class="number">13.00 s/op</td><td class="number">12.00 s/op</td><td class="number">~</td><td class="number">insufficient samples n=1+1</td></tr>`)
+	require.Contains(t, out, "<h3>Geomean</h3>")
+
+	buf.Reset()
+	require.NoError(t, (&HTMLReporter{}).ReportComparison(&buf, analysis.Compare(benchtest.DecodeRun(t, newExample), benchtest.DecodeRun(t, oldExample))))
+	require.Contains(t, buf.String(), `<td class="number improvement">-6.09%</td>`)
+}
+
+func TestHTMLReporter_ReportTable(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, (&HTMLReporter{}).ReportTable(&buf, analysis.Pivot{Columns: []string{"text"}}.Table(benchtest.DecodeRun(t, pivotExample))))
+	require.Contains(t, buf.String(), `<h3>ns/op (median)</h3>
+<table>
+<thead>
+<tr><th>text=digits</th><th>text=twain</th></tr>
+</thead>
+<tbody>
+<tr><td class="number">155.1 µs/op</td><td class="number">6.501 s/op</td></tr>
+</tbody>
+</table>
+`)
+}
@@ -0,0 +1,265 @@
+package report
+
+import (
+	"encoding/xml"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/cep21/benchparse"
+	"github.com/cep21/benchparse/analysis"
+)
+
+// JUnitReporter renders runs and comparisons as JUnit XML, so benchmarks show up in the test results of CI systems.
+// Each benchmark is one testcase, named like analysis.Group.Name with the benchmark function as its classname.
+// Benchmarks with the same name that differ by configuration, like the same benchmark of two packages, have the keys
+// that tell them apart added to the name, like "Decode/size=1e4 (pkg=github.com/a, goos=linux)".  The configuration
+// of the benchmark is the properties of its testcase, and the summary of each unit is its system-out.
+//
+// A benchmark of a comparison fails if any of its units is a significant regression by at least the threshold of the
+// unit.  Benchmarks only in the old run of a comparison were removed, so their testcases are skipped.  Benchmarks of a
+// run never fail.
+type JUnitReporter struct {
+	// Name of the testsuite.  It defaults to "benchmarks".
+	Name string
+	// Threshold is how many percent a unit must regress by, like 5 for 5%, to fail its testcase.  Only significant
+	// regressions fail, so zero fails every significant regression.
+	Threshold float64
+	// UnitThresholds overrides Threshold for each unit it has, like {"B/op": 0, "ns/op": 10}
+	UnitThresholds map[string]float64
+}
+
+// junitRegressionType is the type of the failure of a testcase that regressed
+const junitRegressionType = "regression"
+
+// junitRemovedMessage is the message of a testcase that is skipped because its benchmark is only in the old run
+const junitRemovedMessage = "removed: only in the old run"
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr,omitempty"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name       string           `xml:"name,attr"`
+	ClassName  string           `xml:"classname,attr"`
+	Properties *junitProperties `xml:"properties"`
+	Failure    *junitFailure    `xml:"failure"`
+	Skipped    *junitSkipped    `xml:"skipped"`
+	SystemOut  *junitText       `xml:"system-out"`
+}
+
+type junitProperties struct {
+	Properties []junitProperty `xml:"property"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",cdata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// junitText is the text of an element, as CDATA so new lines are kept as they are
+type junitText struct {
+	Text string `xml:",cdata"`
+}
+
+// ReportRun writes a testsuite with a passing testcase for every benchmark of run.  The system-out of each testcase
+// is the median of each unit, like "ns/op: 154.1 µs/op ± 1%".
+func (j *JUnitReporter) ReportRun(w io.Writer, run *benchparse.Run) error {
+	var testCases []junitTestCase
+	groups := analysis.GroupResults(run.Results)
+	names := junitNames(groups)
+	for _, g := range groups {
+		testCases = append(testCases, newJUnitTestCase(g, names[g], junitSummaries(g)))
+	}
+	return j.write(w, testCases)
+}
+
+// junitSummaries returns the median of each unit of g, one unit per line
+func junitSummaries(g *analysis.Group) string {
+	s := analysis.Summarizer{}
+	var out strings.Builder
+	for _, unit := range g.Units() {
+		out.WriteString(unit + ": " + formatSummary(s.SummarizeGroup(g, unit)) + "\n")
+	}
+	return out.String()
+}
+
+// ReportComparison writes a testsuite with a testcase for every compared benchmark of c, named and described by the
+// benchmark of the new run, followed by a skipped testcase for every benchmark in c.OldOnly.  The message of a
+// failure lists each unit that regressed, like "ns/op: +12.00% (old 154.1 µs/op, new 172.6 µs/op)", and the
+// system-out of each testcase compares every unit.  The system-out of a skipped testcase is the median of each unit
+// in the old run.
+func (j *JUnitReporter) ReportComparison(w io.Writer, c *analysis.Comparison) error {
+	var groups []*analysis.Group
+	indexes := make(map[*analysis.Group]int)
+	for _, row := range c.Rows {
+		if _, exists := indexes[row.New.Group]; !exists {
+			indexes[row.New.Group] = len(groups)
+			groups = append(groups, row.New.Group)
+		}
+	}
+	names := junitNames(append(append([]*analysis.Group(nil), groups...), c.OldOnly...))
+	testCases := make([]junitTestCase, 0, len(groups)+len(c.OldOnly))
+	for _, g := range groups {
+		testCases = append(testCases, newJUnitTestCase(g, names[g], ""))
+	}
+	for _, row := range c.Rows {
+		tc := &testCases[indexes[row.New.Group]]
+		tc.SystemOut.Text += row.Unit + ": old " + formatSummary(row.Old) + ", new " + formatSummary(row.New) +
+			", delta " + row.DeltaString() + ", " + formatTest(row) + "\n"
+		if !j.fails(row) {
+			continue
+		}
+		message := row.Unit + ": " + row.DeltaString() + " (old " + formatValue(row.Old.Median, row.Unit) + ", new " +
+			formatValue(row.New.Median, row.Unit) + ")"
+		if tc.Failure == nil {
+			tc.Failure = &junitFailure{Message: message, Type: junitRegressionType}
+		} else {
+			tc.Failure.Message += "; " + message
+		}
+		tc.Failure.Text += message + ", " + formatTest(row) + ", threshold " + formatThreshold(j.threshold(row.Unit)) + "\n"
+	}
+	for _, g := range c.OldOnly {
+		tc := newJUnitTestCase(g, names[g], junitSummaries(g))
+		tc.Skipped = &junitSkipped{Message: junitRemovedMessage}
+		testCases = append(testCases, tc)
+	}
+	return j.write(w, testCases)
+}
+
+// newJUnitTestCase returns a testcase for g called name, with the configuration of its first result as properties
+func newJUnitTestCase(g *analysis.Group, name string, out string) junitTestCase {
+	ret := junitTestCase{
+		Name:      name,
+		ClassName: g.Results[0].ParsedName().Function,
+		SystemOut: &junitText{Text: out},
+	}
+	if config := g.Results[0].Configuration; config != nil && len(config.Order) > 0 {
+		ret.Properties = &junitProperties{}
+		for _, k := range config.Order {
+			ret.Properties.Properties = append(ret.Properties.Properties, junitProperty{Name: k, Value: config.Contents[k]})
+		}
+	}
+	return ret
+}
+
+// junitNames returns the testcase name of each group.  It is the Name of the group, followed by the keys whose values
+// differ between groups with that Name, if there is more than one.
+func junitNames(groups []*analysis.Group) map[*analysis.Group]string {
+	byName := make(map[string][]*analysis.Group)
+	for _, g := range groups {
+		byName[g.Name()] = append(byName[g.Name()], g)
+	}
+	ret := make(map[*analysis.Group]string, len(groups))
+	for name, same := range byName {
+		keys := differingKeys(same)
+		for _, g := range same {
+			if len(keys) == 0 {
+				ret[g] = name
+				continue
+			}
+			pairs := make([]string, 0, len(keys))
+			for _, k := range keys {
+				pairs = append(pairs, k+"="+g.Keys.Contents[k])
+			}
+			ret[g] = name + " (" + strings.Join(pairs, ", ") + ")"
+		}
+	}
+	return ret
+}
+
+// differingKeys returns the keys that do not have the same value in every group, in the order they first appear.  A
+// group without a key has the value "".
+func differingKeys(groups []*analysis.Group) []string {
+	var ret []string
+	seen := make(map[string]bool)
+	for _, g := range groups {
+		for _, k := range g.Keys.Order {
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			for _, other := range groups {
+				if other.Keys.Contents[k] != g.Keys.Contents[k] {
+					ret = append(ret, k)
+					break
+				}
+			}
+		}
+	}
+	return ret
+}
+
+// Regressions returns the rows of c that fail their testcase: significant regressions by at least the threshold of
+// their unit
+func (j *JUnitReporter) Regressions(c *analysis.Comparison) []analysis.ComparisonRow {
+	var ret []analysis.ComparisonRow
+	for _, row := range c.Rows {
+		if j.fails(row) {
+			ret = append(ret, row)
+		}
+	}
+	return ret
+}
+
+// fails returns true if row is a significant regression by at least the threshold of its unit
+func (j *JUnitReporter) fails(row analysis.ComparisonRow) bool {
+	return changeOf(row) == regression && math.Abs(row.Delta) >= j.threshold(row.Unit)
+}
+
+func (j *JUnitReporter) threshold(unit string) float64 {
+	if threshold, exists := j.UnitThresholds[unit]; exists {
+		return threshold
+	}
+	return j.Threshold
+}
+
+// formatThreshold formats a threshold percent, like "5%"
+func formatThreshold(threshold float64) string {
+	return strconv.FormatFloat(threshold, 'f', -1, 64) + "%"
+}
+
+func (j *JUnitReporter) write(w io.Writer, testCases []junitTestCase) error {
+	suite := junitTestSuite{
+		Name:      j.Name,
+		Tests:     len(testCases),
+		TestCases: testCases,
+	}
+	if suite.Name == "" {
+		suite.Name = "benchmarks"
+	}
+	for _, tc := range testCases {
+		if tc.Failure != nil {
+			suite.Failures++
+		}
+		if tc.Skipped != nil {
+			suite.Skipped++
+		}
+	}
+	b, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, xml.Header+string(b)+"\n")
+	return err
+}
@@ -0,0 +1,158 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cep21/benchparse/analysis"
+	"github.com/cep21/benchparse/internal/benchtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJUnitReporter_ReportRun(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, (&JUnitReporter{Name: "nightly"}).ReportRun(&buf, benchtest.DecodeRun(t, `commit: 7cd9055
+BenchmarkEncode/fast-8 2000 1.5 ns/op 3 B/op
+BenchmarkEncode/slow<b>-8 2000 2500 ns/op
+`)))
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="nightly" tests="2" failures="0">
+    <testcase name="Encode/fast" classname="Encode">
+      <properties>
+        <property name="commit" value="7cd9055"></property>
+      </properties>
+      <system-out><![CDATA[ns/op: 1.500 ns/op
+B/op: 3.000 B/op
+]]></system-out>
+    </testcase>
+    <testcase name="Encode/slow&lt;b&gt;" classname="Encode">
+      <properties>
+        <property name="commit" value="7cd9055"></property>
+      </properties>
+      <system-out><![CDATA[ns/op: 2.500 µs/op
+]]></system-out>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, buf.String())
+}
+
+func TestJUnitReporter_ReportRun_sameName(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, (&JUnitReporter{}).ReportRun(&buf, benchtest.DecodeRun(t, `commit: 7cd9055
+pkg: github.com/cep21/a
+BenchmarkEncode-8 2000 1.5 ns/op
+pkg: github.com/cep21/b
+BenchmarkEncode-8 2000 2.5 ns/op
+goos: linux
+BenchmarkEncode-8 2000 3.5 ns/op
+BenchmarkDecode-8 2000 3.5 ns/op
+`)))
+	var names []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.Contains(line, "<testcase ") {
+			names = append(names, strings.TrimSpace(line))
+		}
+	}
+	require.Equal(t, []string{
+		`<testcase name="Encode (pkg=github.com/cep21/a, goos=)" classname="Encode">`,
+		`<testcase name="Encode (pkg=github.com/cep21/b, goos=)" classname="Encode">`,
+		`<testcase name="Encode (pkg=github.com/cep21/b, goos=linux)" classname="Encode">`,
+		`<testcase name="Decode" classname="Decode">`,
+	}, names)
+}
+
+func TestJUnitReporter_ReportComparison(t *testing.T) {
+	c := analysis.Compare(benchtest.DecodeRun(t, oldExample), benchtest.DecodeRun(t, newExample))
+	var buf bytes.Buffer
+	require.NoError(t, (&JUnitReporter{Threshold: 10, UnitThresholds: map[string]float64{"MB/s": 5}}).ReportComparison(&buf, c))
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="benchmarks" tests="4" failures="1">
+    <testcase name="Decode/text=digits/level=speed/size=1e4" classname="Decode">
+      <properties>
+        <property name="commit" value="7cd9056"></property>
+        <property name="goos" value="linux"></property>
+      </properties>
+      <failure message="MB/s: -6.09% (old 64.84 MB/s, new 60.89 MB/s)" type="regression"><![CDATA[MB/s: -6.09% (old 64.84 MB/s, new 60.89 MB/s), p=0.008 n=5+5, threshold 5%
+]]></failure>
+      <system-out><![CDATA[ns/op: old 154.2 µs/op ± 1%, new 164.2 µs/op ± 1%, delta +6.48%, p=0.008 n=5+5
+MB/s: old 64.84 MB/s ± 1%, new 60.89 MB/s ± 1%, delta -6.09%, p=0.008 n=5+5
+B/op: old 40.42 kB/op ± 0%, new 40.42 kB/op ± 0%, delta ~, p=1.000 n=5+5
+]]></system-out>
+    </testcase>
+    <testcase name="Decode/text=twain/level=speed/size=1e6" classname="Decode">
+      <properties>
+        <property name="commit" value="7cd9056"></property>
+        <property name="goos" value="linux"></property>
+      </properties>
+      <system-out><![CDATA[ns/op: old 13.00 s/op, new 12.00 s/op, delta ~, insufficient samples n=1+1
+MB/s: old 76.92 MB/s, new 83.33 MB/s, delta ~, insufficient samples n=1+1
+B/op: old 4.002 MB/op, new 4.002 MB/op, delta ~, insufficient samples n=1+1
+]]></system-out>
+    </testcase>
+    <testcase name="Encode/fast" classname="Encode">
+      <properties>
+        <property name="commit" value="7cd9056"></property>
+        <property name="goos" value="linux"></property>
+      </properties>
+      <system-out><![CDATA[ns/op: old 1.500 ns/op, new 1.500 ns/op, delta ~, insufficient samples n=1+1
+]]></system-out>
+    </testcase>
+    <testcase name="Encode/slow" classname="Encode">
+      <properties>
+        <property name="commit" value="7cd9056"></property>
+        <property name="goos" value="linux"></property>
+      </properties>
+      <system-out><![CDATA[ns/op: old 2.500 ns/op, new 2.500 ns/op, delta ~, insufficient samples n=1+1
+]]></system-out>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, (&JUnitReporter{}).ReportComparison(&buf, c))
+	require.Contains(t, buf.String(), `<failure message="ns/op: +6.48% (old 154.2 µs/op, new 164.2 µs/op); MB/s: -6.09% (old 64.84 MB/s, new 60.89 MB/s)" type="regression">`)
+}
+
+func TestJUnitReporter_ReportComparison_removed(t *testing.T) {
+	c := analysis.Compare(benchtest.DecodeRun(t, "commit: 7cd9055\nBenchmarkA 1 10 ns/op\nBenchmarkRemoved 1 20 ns/op\n"),
+		benchtest.DecodeRun(t, "commit: 7cd9056\nBenchmarkA 1 10 ns/op\nBenchmarkAdded 1 20 ns/op\n"))
+	var buf bytes.Buffer
+	require.NoError(t, (&JUnitReporter{}).ReportComparison(&buf, c))
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="benchmarks" tests="2" failures="0" skipped="1">
+    <testcase name="A" classname="A">
+      <properties>
+        <property name="commit" value="7cd9056"></property>
+      </properties>
+      <system-out><![CDATA[ns/op: old 10.00 ns/op, new 10.00 ns/op, delta ~, insufficient samples n=1+1
+]]></system-out>
+    </testcase>
+    <testcase name="Removed" classname="Removed">
+      <properties>
+        <property name="commit" value="7cd9055"></property>
+      </properties>
+      <skipped message="removed: only in the old run"></skipped>
+      <system-out><![CDATA[ns/op: 20.00 ns/op
+]]></system-out>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, buf.String())
+}
+
+func TestJUnitReporter_Regressions(t *testing.T) {
+	c := analysis.Compare(benchtest.DecodeRun(t, oldExample), benchtest.DecodeRun(t, newExample))
+	rows := (&JUnitReporter{}).Regressions(c)
+	require.Len(t, rows, 2)
+	require.Equal(t, "ns/op", rows[0].Unit)
+	require.Equal(t, "MB/s", rows[1].Unit)
+	require.Len(t, (&JUnitReporter{Threshold: 6.2}).Regressions(c), 1)
+	require.Empty(t, (&JUnitReporter{Threshold: 10}).Regressions(c))
+	require.Empty(t, (&JUnitReporter{}).Regressions(analysis.Compare(benchtest.DecodeRun(t, newExample), benchtest.DecodeRun(t, oldExample))))
+}
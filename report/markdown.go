@@ -0,0 +1,96 @@
+package report
+
+import (
+	"io"
+	"strings"
+
+	"github.com/cep21/benchparse"
+	"github.com/cep21/benchparse/analysis"
+)
+
+// MarkdownReporter renders runs and comparisons as GitHub-flavored Markdown tables, ready to paste into a pull request
+// description.  Significant improvements are marked with a green circle and significant regressions with a red one.
+type MarkdownReporter struct {
+	// HeadingLevel is the level of the heading above each table, like 3 for "###".  It defaults to 3.
+	HeadingLevel int
+}
+
+// markdownReplacer escapes text that Markdown would otherwise format inside a table cell
+var markdownReplacer = strings.NewReplacer(`\`, `\\`, "|", `\|`, "*", `\*`, "_", `\_`, "`", "\\`")
+
+// ReportRun writes a table of the median of each unit for every benchmark of run.  Configuration every result shares
+// is listed once before the tables.
+func (m *MarkdownReporter) ReportRun(w io.Writer, run *benchparse.Run) error {
+	shared, tables := runTables(run)
+	return m.write(w, shared, tables)
+}
+
+// ReportComparison writes a table comparing the old and new median of each benchmark and unit of c
+func (m *MarkdownReporter) ReportComparison(w io.Writer, c *analysis.Comparison) error {
+	return m.write(w, nil, comparisonTables(c))
+}
+
+// ReportTable writes a pivot table, with a column for each row key and then for each column of t
+func (m *MarkdownReporter) ReportTable(w io.Writer, t *analysis.Table) error {
+	return m.write(w, nil, []table{pivotTable(t)})
+}
+
+func (m *MarkdownReporter) write(w io.Writer, shared []string, tables []table) error {
+	var b strings.Builder
+	for _, s := range shared {
+		b.WriteString("- " + markdownReplacer.Replace(s) + "\n")
+	}
+	for i, t := range tables {
+		if i > 0 || len(shared) > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(strings.Repeat("#", m.headingLevel()) + " " + markdownReplacer.Replace(t.title) + "\n\n")
+		if len(t.shared) > 0 {
+			b.WriteString(markdownReplacer.Replace(strings.Join(t.shared, " ")) + "\n\n")
+		}
+		headers := make([]string, len(t.headers))
+		alignments := make([]string, len(t.headers))
+		for j, h := range t.headers {
+			headers[j] = markdownReplacer.Replace(h)
+			alignments[j] = "--:"
+			if j < t.keyColumns {
+				alignments[j] = ":--"
+			}
+		}
+		writeMarkdownRow(&b, headers)
+		writeMarkdownRow(&b, alignments)
+		for _, row := range t.rows {
+			cells := make([]string, len(row))
+			for j, c := range row {
+				cells[j] = markdownCell(c)
+			}
+			writeMarkdownRow(&b, cells)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (m *MarkdownReporter) headingLevel() int {
+	if m.HeadingLevel <= 0 {
+		return 3
+	}
+	return m.HeadingLevel
+}
+
+// markdownCell formats c, highlighting improvements and regressions
+func markdownCell(c cell) string {
+	text := markdownReplacer.Replace(c.text)
+	switch c.change {
+	case improvement:
+		return "**" + text + "** :green_circle:"
+	case regression:
+		return "**" + text + "** :red_circle:"
+	default:
+		return text
+	}
+}
+
+func writeMarkdownRow(b *strings.Builder, cells []string) {
+	b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+}
@@ -0,0 +1,150 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cep21/benchparse"
+	"github.com/cep21/benchparse/analysis"
+	"github.com/cep21/benchparse/internal/benchtest"
+	"github.com/stretchr/testify/require"
+)
+
+const oldExample = `commit: 7cd9055
+goos: linux
+BenchmarkDecode/text=digits/level=speed/size=1e4-8 100 154125 ns/op 64.88 MB/s 40418 B/op
+BenchmarkDecode/text=digits/level=speed/size=1e4-8 100 155125 ns/op 64.48 MB/s 40418 B/op
+BenchmarkDecode/text=digits/level=speed/size=1e4-8 100 153125 ns/op 65.28 MB/s 40418 B/op
+BenchmarkDecode/text=digits/level=speed/size=1e4-8 100 154525 ns/op 64.71 MB/s 40418 B/op
+BenchmarkDecode/text=digits/level=speed/size=1e4-8 100 154225 ns/op 64.84 MB/s 40418 B/op
+BenchmarkDecode/text=twain/level=speed/size=1e6-8 1 13000839000 ns/op 76.92 MB/s 4001776 B/op
+BenchmarkEncode/fast-8 2000 1.5 ns/op
+BenchmarkEncode/slow-8 2000 2.5 ns/op
+`
+
+const newExample = `commit: 7cd9056
+goos: linux
+BenchmarkDecode/text=digits/level=speed/size=1e4-8 100 164125 ns/op 60.93 MB/s 40418 B/op
+BenchmarkDecode/text=digits/level=speed/size=1e4-8 100 165125 ns/op 60.56 MB/s 40418 B/op
+BenchmarkDecode/text=digits/level=speed/size=1e4-8 100 163125 ns/op 61.30 MB/s 40418 B/op
+BenchmarkDecode/text=digits/level=speed/size=1e4-8 100 164525 ns/op 60.78 MB/s 40418 B/op
+BenchmarkDecode/text=digits/level=speed/size=1e4-8 100 164225 ns/op 60.89 MB/s 40418 B/op
+BenchmarkDecode/text=twain/level=speed/size=1e6-8 1 12000839000 ns/op 83.33 MB/s 4001776 B/op
+BenchmarkEncode/fast-8 2000 1.5 ns/op
+BenchmarkEncode/slow-8 2000 2.5 ns/op
+`
+
+func TestMarkdownReporter_ReportRun(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, (&MarkdownReporter{}).ReportRun(&buf, benchtest.DecodeRun(t, oldExample)))
+	require.Equal(t, `- commit: 7cd9055
+- goos: linux
+
+### Decode
+
+level=speed
+
+| text | size | ns/op | MB/s | B/op |
+| :-- | :-- | --: | --: | --: |
+| digits | 1e4 | 154.2 µs/op ± 1% | 64.84 MB/s ± 1% | 40.42 kB/op ± 0% |
+| twain | 1e6 | 13.00 s/op | 76.92 MB/s | 4.002 MB/op |
+
+### Encode
+
+| name | ns/op |
+| :-- | --: |
+| fast | 1.500 ns/op |
+| slow | 2.500 ns/op |
+`, buf.String())
+}
+
+func TestMarkdownReporter_ReportComparison(t *testing.T) {
+	c := analysis.Compare(benchtest.DecodeRun(t, oldExample), benchtest.DecodeRun(t, newExample))
+	var buf bytes.Buffer
+	require.NoError(t, (&MarkdownReporter{HeadingLevel: 2}).ReportComparison(&buf, c))
+	require.Equal(t, `## Decode (ns/op)
+
+level=speed
+
+| text | size | old | new | delta |  |
+| :-- | :-- | --: | --: | --: | --: |
+| digits | 1e4 | 154.2 µs/op ± 1% | 164.2 µs/op ± 1% | **+6.48%** :red_circle: | p=0.008 n=5+5 |
+| twain | 1e6 | 13.00 s/op | 12.00 s/op | ~ | insufficient samples n=1+1 |
+
+## Decode (MB/s)
+
+level=speed
+
+| text | size | old | new | delta |  |
+| :-- | :-- | --: | --: | --: | --: |
+| digits | 1e4 | 64.84 MB/s ± 1% | 60.89 MB/s ± 1% | **-6.09%** :red_circle: | p=0.008 n=5+5 |
+| twain | 1e6 | 76.92 MB/s | 83.33 MB/s | ~ | insufficient samples n=1+1 |
+
+## Decode (B/op)
+
+level=speed
+
+| text | size | old | new | delta |  |
+| :-- | :-- | --: | --: | --: | --: |
+| digits | 1e4 | 40.42 kB/op ± 0% | 40.42 kB/op ± 0% | ~ | p=1.000 n=5+5 |
+| twain | 1e6 | 4.002 MB/op | 4.002 MB/op | ~ | insufficient samples n=1+1 |
+
+## Encode (ns/op)
+
+| name | old | new | delta |  |
+| :-- | --: | --: | --: | --: |
+| fast | 1.500 ns/op | 1.500 ns/op | ~ | insufficient samples n=1+1 |
+| slow | 2.500 ns/op | 2.500 ns/op | ~ | insufficient samples n=1+1 |
+
+## Geomean
+
+| unit | old | new | delta |
+| :-- | --: | --: | --: |
+| ns/op | 9.312 µs/op | 9.272 µs/op | -0.43% |
+| MB/s | 70.62 MB/s | 71.23 MB/s | +0.86% |
+| B/op | 402.2 kB/op | 402.2 kB/op | +0.00% |
+`, buf.String())
+}
+
+func TestMarkdownReporter_improvement(t *testing.T) {
+	c := analysis.Compare(benchtest.DecodeRun(t, newExample), benchtest.DecodeRun(t, oldExample))
+	var buf bytes.Buffer
+	require.NoError(t, (&MarkdownReporter{}).ReportComparison(&buf, c))
+	require.Contains(t, buf.String(), "| digits | 1e4 | 164.2 µs/op ± 1% | 154.2 µs/op ± 1% | **-6.09%** :green_circle: | p=0.008 n=5+5 |\n")
+	require.Contains(t, buf.String(), "| digits | 1e4 | 60.89 MB/s ± 1% | 64.84 MB/s ± 1% | **+6.49%** :green_circle: | p=0.008 n=5+5 |\n")
+}
+
+func TestMarkdownReporter_escape(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, (&MarkdownReporter{}).ReportRun(&buf, benchtest.DecodeRun(t, `BenchmarkParse_json/q=a|b-8 100 10 ns/op
+BenchmarkParse_json/q=*-8 100 10 ns/op
+`)))
+	require.Equal(t, `### Parse\_json
+
+| q | ns/op |
+| :-- | --: |
+| a\|b | 10.00 ns/op |
+| \* | 10.00 ns/op |
+`, buf.String())
+}
+
+func TestMarkdownReporter_empty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, (&MarkdownReporter{}).ReportRun(&buf, &benchparse.Run{}))
+	require.Equal(t, "", buf.String())
+	require.NoError(t, (&MarkdownReporter{}).ReportComparison(&buf, &analysis.Comparison{}))
+	require.Equal(t, "", buf.String())
+}
+
+func TestMarkdownReporter_ReportTable(t *testing.T) {
+	p := analysis.Pivot{Rows: []string{"text"}, Columns: []string{"level", "size"}, Aggregation: analysis.AggregationMax}
+	var buf bytes.Buffer
+	require.NoError(t, (&MarkdownReporter{}).ReportTable(&buf, p.Table(benchtest.DecodeRun(t, pivotExample))))
+	require.Equal(t, `### ns/op (max)
+
+| text | level=speed/size=1e4 | level=speed/size=1e6 | level=best/size=1e6 |
+| :-- | --: | --: | --: |
+| digits | 155.1 µs/op | 15.00 s/op |  |
+| twain | 164.1 µs/op |  | 13.00 s/op |
+`, buf.String())
+}
@@ -0,0 +1,351 @@
+package report
+
+import (
+	"strings"
+
+	"github.com/cep21/benchparse"
+	"github.com/cep21/benchparse/analysis"
+)
+
+// change is whether a cell of a comparison is a significant improvement or regression
+type change int
+
+const (
+	noChange change = iota
+	improvement
+	regression
+)
+
+// cell is a single formatted value of a table
+type cell struct {
+	text   string
+	change change
+}
+
+// table is a single table of a report
+type table struct {
+	// title is the heading of the table and shared are the key=value pairs every row of the table has
+	title  string
+	shared []string
+	// headers of each column.  The first keyColumns columns describe the benchmark of a row, and every column after
+	// them is a number.
+	headers    []string
+	keyColumns int
+	rows       [][]cell
+}
+
+// pair is a single key=value pair that describes a benchmark
+type pair struct {
+	key   string
+	value string
+}
+
+// item is what tells a row of a table apart from the other rows: the benchmark function, its sub benchmarks, and its
+// configuration
+type item struct {
+	function string
+	// bare are the sub benchmarks without a "=", which are not keys
+	bare []string
+	// keys are the configuration keys followed by the key=value sub benchmarks.  Like AllKeyValuePairs, sub benchmarks
+	// replace configuration keys with the same key.
+	keys []pair
+}
+
+// newItem returns the item of r.  Only configuration keys that include returns true for are part of the item.
+func newItem(r benchparse.BenchmarkResult, include func(key string) bool) item {
+	name := r.ParsedName()
+	ret := item{
+		function: name.Function,
+	}
+	if r.Configuration != nil {
+		for _, k := range r.Configuration.Order {
+			if include(k) {
+				ret.keys = setPair(ret.keys, k, r.Configuration.Contents[k])
+			}
+		}
+	}
+	for _, sub := range name.SubBenchmarks {
+		sections := strings.SplitN(sub, "=", 2)
+		if len(sections) == 2 {
+			ret.keys = setPair(ret.keys, sections[0], sections[1])
+		} else {
+			ret.bare = append(ret.bare, sub)
+		}
+	}
+	return ret
+}
+
+// setPair sets key to value inside pairs.  New keys go at the end.
+func setPair(pairs []pair, key string, value string) []pair {
+	for i := range pairs {
+		if pairs[i].key == key {
+			pairs[i].value = value
+			return pairs
+		}
+	}
+	return append(pairs, pair{key: key, value: value})
+}
+
+// lookup returns the value of key inside pairs
+func lookup(pairs []pair, key string) (string, bool) {
+	for _, p := range pairs {
+		if p.key == key {
+			return p.value, true
+		}
+	}
+	return "", false
+}
+
+// keyLayout is how the items of a table are told apart.  Keys with the same value for every item are shared by the
+// table, and every other key is a column.
+type keyLayout struct {
+	shared  []string
+	columns []string
+	// bare is true if any item has sub benchmarks without a "=", which get a column of their own
+	bare bool
+}
+
+// bareHeader is the header of the column of sub benchmarks without a "="
+const bareHeader = "name"
+
+func newKeyLayout(items []item) keyLayout {
+	var ret keyLayout
+	var keys []string
+	seen := make(map[string]struct{})
+	for _, it := range items {
+		ret.bare = ret.bare || len(it.bare) > 0
+		for _, p := range it.keys {
+			if _, exists := seen[p.key]; !exists {
+				seen[p.key] = struct{}{}
+				keys = append(keys, p.key)
+			}
+		}
+	}
+	for _, k := range keys {
+		first, _ := lookup(items[0].keys, k)
+		varies := false
+		for _, it := range items {
+			if v, exists := lookup(it.keys, k); !exists || v != first {
+				varies = true
+				break
+			}
+		}
+		if varies {
+			ret.columns = append(ret.columns, k)
+		} else {
+			ret.shared = append(ret.shared, k+"="+first)
+		}
+	}
+	return ret
+}
+
+// headers returns the headers of the key columns
+func (l keyLayout) headers() []string {
+	var ret []string
+	if l.bare {
+		ret = append(ret, bareHeader)
+	}
+	return append(ret, l.columns...)
+}
+
+// cells returns the key columns of it.  Keys it does not have are empty.
+func (l keyLayout) cells(it item) []cell {
+	var ret []cell
+	if l.bare {
+		ret = append(ret, cell{text: strings.Join(it.bare, "/")})
+	}
+	for _, k := range l.columns {
+		v, _ := lookup(it.keys, k)
+		ret = append(ret, cell{text: v})
+	}
+	return ret
+}
+
+// functionOrder groups values by benchmark function, in the order each function first appears.  function returns the
+// benchmark function of the value at index i.
+func functionOrder(n int, function func(i int) string) [][]int {
+	var ret [][]int
+	indexes := make(map[string]int)
+	for i := 0; i < n; i++ {
+		f := function(i)
+		idx, exists := indexes[f]
+		if !exists {
+			idx = len(ret)
+			indexes[f] = idx
+			ret = append(ret, nil)
+		}
+		ret[idx] = append(ret[idx], i)
+	}
+	return ret
+}
+
+// runTables returns the configuration every result of run shares, and one table for each benchmark function of run
+// with a column for the median of each unit
+func runTables(run *benchparse.Run) ([]string, []table) {
+	runPairs := runConfiguration(run.Results)
+	include := func(key string) bool {
+		_, exists := lookup(runPairs, key)
+		return !exists
+	}
+	shared := make([]string, 0, len(runPairs))
+	for _, p := range runPairs {
+		shared = append(shared, p.key+": "+p.value)
+	}
+	groups := analysis.GroupResults(run.Results)
+	var tables []table
+	s := analysis.Summarizer{}
+	for _, indexes := range functionOrder(len(groups), func(i int) string {
+		return groups[i].Results[0].ParsedName().Function
+	}) {
+		items := make([]item, 0, len(indexes))
+		var units []string
+		seenUnits := make(map[string]struct{})
+		for _, i := range indexes {
+			items = append(items, newItem(groups[i].Results[0], include))
+			for _, unit := range groups[i].Units() {
+				if _, exists := seenUnits[unit]; !exists {
+					seenUnits[unit] = struct{}{}
+					units = append(units, unit)
+				}
+			}
+		}
+		layout := newKeyLayout(items)
+		t := table{
+			title:      items[0].function,
+			shared:     layout.shared,
+			headers:    append(layout.headers(), units...),
+			keyColumns: len(layout.headers()),
+		}
+		for j, i := range indexes {
+			row := layout.cells(items[j])
+			for _, unit := range units {
+				if len(groups[i].Values(unit)) == 0 {
+					row = append(row, cell{})
+					continue
+				}
+				row = append(row, cell{text: formatSummary(s.SummarizeGroup(groups[i], unit))})
+			}
+			t.rows = append(t.rows, row)
+		}
+		tables = append(tables, t)
+	}
+	return shared, tables
+}
+
+// runConfiguration returns the configuration pairs every result has and that are not part of any benchmark name
+func runConfiguration(results []benchparse.BenchmarkResult) []pair {
+	if len(results) == 0 || results[0].Configuration == nil {
+		return nil
+	}
+	var ret []pair
+	first := results[0].Configuration
+	for _, k := range first.Order {
+		shared := true
+		for _, r := range results {
+			if r.Configuration == nil || r.Configuration.Contents[k] != first.Contents[k] {
+				shared = false
+				break
+			}
+			if _, exists := r.NameAsKeyValue().Contents[k]; exists {
+				shared = false
+				break
+			}
+		}
+		if shared {
+			ret = append(ret, pair{key: k, value: first.Contents[k]})
+		}
+	}
+	return ret
+}
+
+// comparisonHeaders are the headers of the number columns of a comparison table
+var comparisonHeaders = []string{"old", "new", "delta", ""}
+
+// comparisonTables returns one table for each benchmark function and unit of c, and a table of the geometric means
+// of each unit if any unit compared more than one benchmark
+func comparisonTables(c *analysis.Comparison) []table {
+	type tableKey struct {
+		function string
+		unit     string
+	}
+	var tables []table
+	rowKey := func(i int) tableKey {
+		return tableKey{function: c.Rows[i].Old.Group.Results[0].ParsedName().Function, unit: c.Rows[i].Unit}
+	}
+	for _, indexes := range functionOrder(len(c.Rows), func(i int) string {
+		k := rowKey(i)
+		return k.function + " " + k.unit
+	}) {
+		items := make([]item, 0, len(indexes))
+		for _, i := range indexes {
+			keys := c.Rows[i].Keys
+			items = append(items, newItem(c.Rows[i].Old.Group.Results[0], func(key string) bool {
+				_, exists := keys.Contents[key]
+				return exists
+			}))
+		}
+		layout := newKeyLayout(items)
+		k := rowKey(indexes[0])
+		t := table{
+			title:      k.function + " (" + k.unit + ")",
+			shared:     layout.shared,
+			headers:    append(layout.headers(), comparisonHeaders...),
+			keyColumns: len(layout.headers()),
+		}
+		for j, i := range indexes {
+			row := c.Rows[i]
+			t.rows = append(t.rows, append(layout.cells(items[j]),
+				cell{text: formatSummary(row.Old)},
+				cell{text: formatSummary(row.New)},
+				cell{text: row.DeltaString(), change: changeOf(row)},
+				cell{text: formatTest(row)},
+			))
+		}
+		tables = append(tables, t)
+	}
+	if len(c.Rows) > len(c.Geomeans) {
+		t := table{
+			title:      "Geomean",
+			headers:    []string{"unit", "old", "new", "delta"},
+			keyColumns: 1,
+		}
+		for _, g := range c.Geomeans {
+			t.rows = append(t.rows, []cell{
+				{text: g.Unit},
+				{text: formatValue(g.Old, g.Unit)},
+				{text: formatValue(g.New, g.Unit)},
+				{text: g.DeltaString()},
+			})
+		}
+		tables = append(tables, t)
+	}
+	return tables
+}
+
+// pivotTable returns the table of a pivot table, titled with its unit and aggregation like "ns/op (median)".  Empty
+// cells are left blank.
+func pivotTable(p *analysis.Table) table {
+	t := table{
+		title:      p.Unit + " (" + p.Aggregation.String() + ")",
+		headers:    append([]string(nil), p.RowKeys...),
+		keyColumns: len(p.RowKeys),
+	}
+	for j := range p.Columns {
+		t.headers = append(t.headers, p.ColumnName(j))
+	}
+	for i, values := range p.Rows {
+		row := make([]cell, 0, len(values)+len(p.Columns))
+		for _, v := range values {
+			row = append(row, cell{text: v})
+		}
+		for _, c := range p.Cells[i] {
+			if c.Empty() {
+				row = append(row, cell{})
+				continue
+			}
+			row = append(row, cell{text: formatValue(c.Value, p.Unit)})
+		}
+		t.rows = append(t.rows, row)
+	}
+	return t
+}
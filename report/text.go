@@ -0,0 +1,90 @@
+package report
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cep21/benchparse"
+	"github.com/cep21/benchparse/analysis"
+)
+
+// TextReporter renders runs and comparisons as plain text tables for a terminal, with the columns of each table
+// aligned.  Numbers are right aligned and everything else is left aligned.
+type TextReporter struct{}
+
+// textColumnSeparator is the space between two columns
+const textColumnSeparator = "  "
+
+// ReportRun writes a table of the median of each unit for every benchmark of run.  Configuration every result shares
+// is listed once before the tables.
+func (tr *TextReporter) ReportRun(w io.Writer, run *benchparse.Run) error {
+	shared, tables := runTables(run)
+	return tr.write(w, shared, tables)
+}
+
+// ReportComparison writes a table comparing the old and new median of each benchmark and unit of c
+func (tr *TextReporter) ReportComparison(w io.Writer, c *analysis.Comparison) error {
+	return tr.write(w, nil, comparisonTables(c))
+}
+
+// ReportTable writes a pivot table, with a column for each row key and then for each column of t
+func (tr *TextReporter) ReportTable(w io.Writer, t *analysis.Table) error {
+	return tr.write(w, nil, []table{pivotTable(t)})
+}
+
+func (tr *TextReporter) write(w io.Writer, shared []string, tables []table) error {
+	var b strings.Builder
+	for _, s := range shared {
+		b.WriteString(s + "\n")
+	}
+	for i, t := range tables {
+		if i > 0 || len(shared) > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(t.title + "\n")
+		if len(t.shared) > 0 {
+			b.WriteString(strings.Join(t.shared, " ") + "\n")
+		}
+		widths := make([]int, len(t.headers))
+		for j, h := range t.headers {
+			widths[j] = utf8.RuneCountInString(h)
+		}
+		for _, row := range t.rows {
+			for j, c := range row {
+				if n := utf8.RuneCountInString(c.text); n > widths[j] {
+					widths[j] = n
+				}
+			}
+		}
+		cells := make([]string, len(t.headers))
+		copy(cells, t.headers)
+		writeTextRow(&b, cells, widths, t.keyColumns)
+		for _, row := range t.rows {
+			for j, c := range row {
+				cells[j] = c.text
+			}
+			writeTextRow(&b, cells, widths, t.keyColumns)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeTextRow writes cells padded to widths.  The first keyColumns cells are left aligned and the rest right aligned.
+// Trailing white space is removed.
+func writeTextRow(b *strings.Builder, cells []string, widths []int, keyColumns int) {
+	var line strings.Builder
+	for j, c := range cells {
+		if j > 0 {
+			line.WriteString(textColumnSeparator)
+		}
+		padding := strings.Repeat(" ", widths[j]-utf8.RuneCountInString(c))
+		if j < keyColumns {
+			line.WriteString(c + padding)
+		} else {
+			line.WriteString(padding + c)
+		}
+	}
+	b.WriteString(strings.TrimRight(line.String(), " ") + "\n")
+}
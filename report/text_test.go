@@ -0,0 +1,61 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cep21/benchparse/analysis"
+	"github.com/cep21/benchparse/internal/benchtest"
+	"github.com/stretchr/testify/require"
+)
+
+const pivotExample = `commit: 7cd9055
+BenchmarkDecode/text=digits/level=speed/size=1e4-8 100 154125 ns/op
+BenchmarkDecode/text=digits/level=speed/size=1e4-8 100 155125 ns/op
+BenchmarkDecode/text=digits/level=speed/size=1e6-8 1 15000839000 ns/op
+BenchmarkDecode/text=twain/level=speed/size=1e4-8 100 164125 ns/op
+BenchmarkDecode/text=twain/level=best/size=1e6-8 1 13000839000 ns/op
+`
+
+func TestTextReporter_ReportRun(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, (&TextReporter{}).ReportRun(&buf, benchtest.DecodeRun(t, oldExample)))
+	require.Equal(t, `commit: 7cd9055
+goos: linux
+
+Decode
+level=speed
+text    size             ns/op             MB/s              B/op
+digits  1e4   154.2 µs/op ± 1%  64.84 MB/s ± 1%  40.42 kB/op ± 0%
+twain   1e6         13.00 s/op       76.92 MB/s       4.002 MB/op
+
+Encode
+name        ns/op
+fast  1.500 ns/op
+slow  2.500 ns/op
+`, buf.String())
+}
+
+func TestTextReporter_ReportComparison(t *testing.T) {
+	c := analysis.Compare(benchtest.DecodeRun(t, oldExample), benchtest.DecodeRun(t, newExample))
+	var buf bytes.Buffer
+	require.NoError(t, (&TextReporter{}).ReportComparison(&buf, c))
+	require.Contains(t, buf.String(), `Decode (ns/op)
+level=speed
+text    size               old               new   delta
+digits  1e4   154.2 µs/op ± 1%  164.2 µs/op ± 1%  +6.48%               p=0.008 n=5+5
+twain   1e6         13.00 s/op        12.00 s/op       ~  insufficient samples n=1+1
+`)
+}
+
+func TestTextReporter_ReportTable(t *testing.T) {
+	p := analysis.Pivot{Rows: []string{"text", "level"}, Columns: []string{"size"}}
+	var buf bytes.Buffer
+	require.NoError(t, (&TextReporter{}).ReportTable(&buf, p.Table(benchtest.DecodeRun(t, pivotExample))))
+	require.Equal(t, `ns/op (median)
+text    level     size=1e4    size=1e6
+digits  speed  154.6 µs/op  15.00 s/op
+twain   speed  164.1 µs/op
+twain   best                13.00 s/op
+`, buf.String())
+}
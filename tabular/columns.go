@@ -0,0 +1,180 @@
+package tabular
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/cep21/benchparse"
+)
+
+const (
+	// NameHeader is the header of the column holding the full benchmark name
+	NameHeader = "name"
+	// IterationsHeader is the header of the column holding the iterations of a benchmark
+	IterationsHeader = "iterations"
+	// ConfigurationPrefix is the prefix of annotated headers of configuration key columns
+	ConfigurationPrefix = "config:"
+	// NamePrefix is the prefix of annotated headers of columns for key=value pairs inside the benchmark name
+	NamePrefix = "name:"
+	// UnitPrefix is the prefix of annotated headers of unit columns
+	UnitPrefix = "unit:"
+)
+
+// columnKind is the part of a BenchmarkResult a column holds
+type columnKind int
+
+const (
+	columnName columnKind = iota
+	columnIterations
+	// columnKey is a key of AllKeyValuePairs, which can come from either the configuration or the name
+	columnKey
+	columnConfiguration
+	columnNameKey
+	columnUnit
+)
+
+// column is a single column of a table.  key is the configuration key, name key, or unit of the column.
+type column struct {
+	kind columnKind
+	key  string
+}
+
+// header returns the header of the column.  Annotated headers say where the column came from.
+func (c column) header(annotate bool) string {
+	switch c.kind {
+	case columnName:
+		return NameHeader
+	case columnIterations:
+		return IterationsHeader
+	}
+	if !annotate {
+		return c.key
+	}
+	switch c.kind {
+	case columnConfiguration, columnKey:
+		return ConfigurationPrefix + c.key
+	case columnNameKey:
+		return NamePrefix + c.key
+	default:
+		return UnitPrefix + c.key
+	}
+}
+
+// headers returns the header of each column.  Without annotate, columns whose plain header is the same as the header
+// of another column are annotated, so every header is unique.
+func headers(cols []column, annotate bool) []string {
+	ret := make([]string, len(cols))
+	count := make(map[string]int, len(cols))
+	for i, c := range cols {
+		ret[i] = c.header(annotate)
+		count[ret[i]]++
+	}
+	if annotate {
+		return ret
+	}
+	for i, c := range cols {
+		if count[ret[i]] > 1 && c.kind != columnName && c.kind != columnIterations {
+			ret[i] = c.header(true)
+		}
+	}
+	return ret
+}
+
+// cell returns the value of the column for r.  Missing values are empty.
+func (c column) cell(r benchparse.BenchmarkResult) string {
+	switch c.kind {
+	case columnName:
+		return r.Name
+	case columnIterations:
+		return strconv.Itoa(r.Iterations)
+	case columnUnit:
+		if v, exists := r.ValueByUnit(c.key); exists {
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}
+		return ""
+	}
+	if c.kind != columnConfiguration {
+		if v, exists := lookup(nameKeys(r), c.key); exists {
+			return v
+		}
+		if c.kind == columnNameKey {
+			return ""
+		}
+	}
+	if r.Configuration == nil {
+		return ""
+	}
+	return r.Configuration.Contents[c.key]
+}
+
+// columns returns the columns of run: the name, the iterations, each key, then each unit.  Keys and units are in the
+// order they are first seen.  If separateKeys is true, a configuration key and a name key with the same key are
+// different columns.
+func columns(run *benchparse.Run, separateKeys bool) []column {
+	ret := []column{{kind: columnName}, {kind: columnIterations}}
+	var units []column
+	seen := make(map[column]bool)
+	for _, r := range run.Results {
+		for _, c := range keyColumns(r, separateKeys) {
+			if !seen[c] {
+				seen[c] = true
+				ret = append(ret, c)
+			}
+		}
+		for _, v := range r.Values {
+			c := column{kind: columnUnit, key: v.Unit}
+			if !seen[c] {
+				seen[c] = true
+				units = append(units, c)
+			}
+		}
+	}
+	return append(ret, units...)
+}
+
+// keyColumns returns a column for each configuration key and name key of r, in the order of AllKeyValuePairs
+func keyColumns(r benchparse.BenchmarkResult, separateKeys bool) []column {
+	configurationKind, nameKind := columnKey, columnKey
+	if separateKeys {
+		configurationKind, nameKind = columnConfiguration, columnNameKey
+	}
+	var ret []column
+	if r.Configuration != nil {
+		for _, k := range r.Configuration.Order {
+			ret = append(ret, column{kind: configurationKind, key: k})
+		}
+	}
+	for _, p := range nameKeys(r) {
+		ret = append(ret, column{kind: nameKind, key: p.key})
+	}
+	return ret
+}
+
+// pair is a single key=value pair of a benchmark name
+type pair struct {
+	key   string
+	value string
+}
+
+// nameKeys returns the key=value pairs of the sub benchmarks of r, without the "-N" suffix.  Unlike
+// NameAsKeyValue, sub benchmarks without a "=" are not keys, since the name column already holds them.
+func nameKeys(r benchparse.BenchmarkResult) []pair {
+	var ret []pair
+	for _, sub := range r.ParsedName().SubBenchmarks {
+		sections := strings.SplitN(sub, "=", 2)
+		if len(sections) == 2 {
+			ret = append(ret, pair{key: sections[0], value: sections[1]})
+		}
+	}
+	return ret
+}
+
+// lookup returns the value of the last pair with key.  Like NameAsKeyValue, later pairs win.
+func lookup(pairs []pair, key string) (string, bool) {
+	for i := len(pairs) - 1; i >= 0; i-- {
+		if pairs[i].key == key {
+			return pairs[i].value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,289 @@
+package tabular
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/cep21/benchparse"
+)
+
+// Decoder reads a table, like the ones an annotated Encoder writes, back into a Run.  The header must mark what each
+// column is.  The NameHeader column holds the benchmark name and is required.  The IterationsHeader column is optional
+// and defaults to 1.  Every other header starts with ConfigurationPrefix, NamePrefix, or UnitPrefix.
+//
+// Empty cells are missing values: configuration keys and name keys that are empty are left out of the result, as are
+// units that are empty.  Leading spaces and tabs of configuration cells are removed, like they are from the values of
+// configuration lines, so a configuration cell of only spaces is empty.  A name key is added to the benchmark name as a "/key=value" sub benchmark, before any "-N"
+// suffix, unless the name already has that key.  Results with the same configuration as the row before them share the
+// same Configuration.
+type Decoder struct {
+	// Comma is the field delimiter.  It defaults to ','.  Use '\t' for TSV.
+	Comma rune
+}
+
+var (
+	// ErrNoNameColumn is returned for tables without a NameHeader column
+	ErrNoNameColumn = errors.New("invalid table: no name column")
+	// ErrUnannotatedHeader is returned for headers that are not NameHeader or IterationsHeader and do not start with
+	// ConfigurationPrefix, NamePrefix, or UnitPrefix
+	ErrUnannotatedHeader = errors.New("invalid table: header is not annotated")
+	// ErrDuplicateHeader is returned for tables with the same header more than once
+	ErrDuplicateHeader = errors.New("invalid table: duplicate header")
+	// ErrInvalidConfigurationKey is returned for ConfigurationPrefix headers with a key that has a ":"
+	ErrInvalidConfigurationKey = errors.New("invalid table: configuration key has :")
+	// ErrInvalidUnit is returned for UnitPrefix headers with a unit that is empty or has spaces
+	ErrInvalidUnit = errors.New("invalid table: unit is empty or has spaces")
+	// ErrInvalidNameKey is returned for NamePrefix headers with a key that is empty or has spaces, "/", or "="
+	ErrInvalidNameKey = errors.New("invalid table: name key is empty or has spaces, / or =")
+	// ErrNameSpaces is returned for benchmark names that contain white space
+	ErrNameSpaces = errors.New("invalid row: name has spaces")
+	// ErrNoValues is returned for rows without a value for any unit
+	ErrNoValues = errors.New("invalid row: no values")
+)
+
+// RowError describes a single row of a table that could not be decoded
+type RowError struct {
+	// Row is the 1 based row number of the table, counting the header as row 1
+	Row int
+	// Header is the header of the column with the invalid cell.  It is empty if the row as a whole is invalid.
+	Header string
+	// Err is the reason the row was rejected.  It is one of the exported Err values of this package or of benchparse,
+	// or a *strconv.NumError for numbers that could not be parsed.
+	Err error
+}
+
+func (r *RowError) Error() string {
+	if r.Header == "" {
+		return fmt.Sprintf("row %d: %s", r.Row, r.Err)
+	}
+	return fmt.Sprintf("row %d: column %q: %s", r.Row, r.Header, r.Err)
+}
+
+// Unwrap returns the reason the row was rejected
+func (r *RowError) Unwrap() error {
+	return r.Err
+}
+
+// Decode reads a table from in into a Run.  Headers without a name column, or that are not annotated or are
+// duplicates, are returned as is.  Keys and units of headers that cannot be written in the benchmark format, and
+// invalid rows, are returned as a *RowError.
+func (d Decoder) Decode(in io.Reader) (*benchparse.Run, error) {
+	cr := csv.NewReader(in)
+	if d.Comma != 0 {
+		cr.Comma = d.Comma
+	}
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, ErrNoNameColumn
+	}
+	if err != nil {
+		return nil, err
+	}
+	cols, err := parseHeader(header)
+	if err != nil {
+		return nil, err
+	}
+	ret := &benchparse.Run{}
+	var previousConfig *benchparse.OrderedStringStringMap
+	for rowNumber := 2; ; rowNumber++ {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return ret, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		result, rowErr := decodeRow(cols, header, row)
+		if rowErr != nil {
+			rowErr.Row = rowNumber
+			return nil, rowErr
+		}
+		if equalConfiguration(previousConfig, result.Configuration) {
+			result.Configuration = previousConfig
+		}
+		previousConfig = result.Configuration
+		ret.Results = append(ret.Results, result)
+	}
+}
+
+// parseHeader returns the column of each header
+func parseHeader(header []string) ([]column, error) {
+	ret := make([]column, 0, len(header))
+	seen := make(map[column]bool)
+	hasName := false
+	for i, h := range header {
+		var c column
+		switch {
+		case h == NameHeader:
+			c = column{kind: columnName}
+			hasName = true
+		case h == IterationsHeader:
+			c = column{kind: columnIterations}
+		case strings.HasPrefix(h, ConfigurationPrefix):
+			c = column{kind: columnConfiguration, key: h[len(ConfigurationPrefix):]}
+		case strings.HasPrefix(h, NamePrefix):
+			c = column{kind: columnNameKey, key: h[len(NamePrefix):]}
+		case strings.HasPrefix(h, UnitPrefix):
+			c = column{kind: columnUnit, key: h[len(UnitPrefix):]}
+		default:
+			return nil, ErrUnannotatedHeader
+		}
+		if err := validateKey(c); err != nil {
+			return nil, &RowError{Row: 1, Header: header[i], Err: err}
+		}
+		if seen[c] {
+			return nil, ErrDuplicateHeader
+		}
+		seen[c] = true
+		ret = append(ret, c)
+	}
+	if !hasName {
+		return nil, ErrNoNameColumn
+	}
+	return ret, nil
+}
+
+// validateKey returns an error if the key of c cannot be written in the benchmark format.  Configuration keys follow
+// the rules of configuration lines and cannot have the ":" that ends the key of a line, units cannot have spaces, and name keys cannot have the separators of a
+// "/key=value" sub benchmark.
+func validateKey(c column) error {
+	switch c.kind {
+	case columnConfiguration:
+		switch {
+		case c.key == "":
+			return benchparse.ErrInvalidKeyValueEmpty
+		case !unicode.IsLower(rune(c.key[0])):
+			return benchparse.ErrInvalidKeyValueLowercase
+		case strings.IndexFunc(c.key, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsUpper(r) }) != -1:
+			return benchparse.ErrInvalidKeyValueSpaces
+		case strings.Contains(c.key, ":"):
+			return ErrInvalidConfigurationKey
+		}
+	case columnUnit:
+		if c.key == "" || strings.IndexFunc(c.key, unicode.IsSpace) != -1 {
+			return ErrInvalidUnit
+		}
+	case columnNameKey:
+		if c.key == "" || strings.IndexFunc(c.key, func(r rune) bool { return unicode.IsSpace(r) || r == '/' || r == '=' }) != -1 {
+			return ErrInvalidNameKey
+		}
+	}
+	return nil
+}
+
+// decodeRow builds the result of a single row.  The Row of any returned error is not set.
+func decodeRow(cols []column, header []string, row []string) (benchparse.BenchmarkResult, *RowError) {
+	ret := benchparse.BenchmarkResult{
+		Iterations:    1,
+		Configuration: &benchparse.OrderedStringStringMap{},
+	}
+	var names []pair
+	for i, c := range cols {
+		cell := row[i]
+		switch c.kind {
+		case columnName:
+			ret.Name = cell
+		case columnIterations:
+			if cell == "" {
+				continue
+			}
+			iterations, err := strconv.Atoi(cell)
+			if err != nil {
+				return ret, &RowError{Header: header[i], Err: err}
+			}
+			ret.Iterations = iterations
+		case columnConfiguration:
+			cell = strings.TrimLeft(cell, " \t")
+			if cell == "" {
+				continue
+			}
+			// "There are no restrictions on value, except that it cannot contain a newline character"
+			if strings.ContainsAny(cell, "\r\n") {
+				return ret, &RowError{Header: header[i], Err: benchparse.ErrInvalidKeyValueReturn}
+			}
+			if ret.Configuration.Contents == nil {
+				ret.Configuration.Contents = make(map[string]string)
+			}
+			ret.Configuration.Contents[c.key] = cell
+			ret.Configuration.Order = append(ret.Configuration.Order, c.key)
+		case columnNameKey:
+			if cell != "" {
+				names = append(names, pair{key: c.key, value: cell})
+			}
+		case columnUnit:
+			if cell == "" {
+				continue
+			}
+			value, err := strconv.ParseFloat(cell, 64)
+			if err != nil {
+				return ret, &RowError{Header: header[i], Err: err}
+			}
+			ret.Values = append(ret.Values, benchparse.ValueUnitPair{Value: value, Unit: c.key})
+		}
+	}
+	ret.Name = addNameKeys(ret, names)
+	// Name keys are part of the name, so a name key value with spaces is a name with spaces
+	if err := validateName(ret.Name); err != nil {
+		return ret, &RowError{Header: NameHeader, Err: err}
+	}
+	if len(ret.Values) == 0 {
+		return ret, &RowError{Err: ErrNoValues}
+	}
+	return ret, nil
+}
+
+// validateName returns an error if name cannot be written as the name of a benchmark result line
+func validateName(name string) error {
+	if !strings.HasPrefix(name, "Benchmark") {
+		return benchparse.ErrNoPrefixBenchmark
+	}
+	if name != "Benchmark" && !unicode.IsUpper(rune(name[len("Benchmark")])) {
+		return benchparse.ErrUpperAfterBench
+	}
+	if strings.IndexFunc(name, unicode.IsSpace) != -1 {
+		return ErrNameSpaces
+	}
+	return nil
+}
+
+// addNameKeys returns the name of r with a "/key=value" sub benchmark for each pair the name does not already have.
+// They go before the "-N" suffix of the name.
+func addNameKeys(r benchparse.BenchmarkResult, pairs []pair) string {
+	existing := nameKeys(r)
+	var added strings.Builder
+	for _, p := range pairs {
+		if _, exists := lookup(existing, p.key); exists {
+			continue
+		}
+		added.WriteString("/" + p.key + "=" + p.value)
+	}
+	if added.Len() == 0 {
+		return r.Name
+	}
+	if !r.ParsedName().HasProcs {
+		return r.Name + added.String()
+	}
+	dash := strings.LastIndex(r.Name, "-")
+	return r.Name[:dash] + added.String() + r.Name[dash:]
+}
+
+// equalConfiguration returns true if both configurations have the same pairs in the same order
+func equalConfiguration(a *benchparse.OrderedStringStringMap, b *benchparse.OrderedStringStringMap) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if len(a.Order) != len(b.Order) {
+		return false
+	}
+	for i, k := range a.Order {
+		if k != b.Order[i] || a.Contents[k] != b.Contents[k] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package tabular
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cep21/benchparse"
+	"github.com/cep21/benchparse/internal/benchtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoder_Decode(t *testing.T) {
+	run, err := Decoder{}.Decode(strings.NewReader(`name,iterations,config:commit,config:goos,name:size,unit:ns/op,unit:B/op
+BenchmarkDecode-8,100,7cd9055,linux,1e4,154125,40418
+BenchmarkDecode/size=1e6-8,1,7cd9055,linux,1e4,13000839000,
+BenchmarkEncode,,7cd9056,  ,,1.5,
+`))
+	require.NoError(t, err)
+	require.Len(t, run.Results, 3)
+
+	require.Equal(t, "BenchmarkDecode/size=1e4-8", run.Results[0].Name)
+	require.Equal(t, 100, run.Results[0].Iterations)
+	require.Equal(t, []benchparse.ValueUnitPair{{Value: 154125, Unit: "ns/op"}, {Value: 40418, Unit: "B/op"}}, run.Results[0].Values)
+	require.Equal(t, []string{"commit", "goos"}, run.Results[0].Configuration.Order)
+
+	require.Equal(t, "BenchmarkDecode/size=1e6-8", run.Results[1].Name)
+	require.Equal(t, []benchparse.ValueUnitPair{{Value: 13000839000, Unit: "ns/op"}}, run.Results[1].Values)
+	require.True(t, run.Results[0].Configuration == run.Results[1].Configuration)
+
+	require.Equal(t, "BenchmarkEncode", run.Results[2].Name)
+	require.Equal(t, 1, run.Results[2].Iterations)
+	require.Equal(t, map[string]string{"commit": "7cd9056"}, run.Results[2].Configuration.Contents)
+
+	var buf bytes.Buffer
+	require.NoError(t, (&benchparse.Encoder{}).Encode(&buf, run))
+	require.Equal(t, `commit: 7cd9055
+goos: linux
+BenchmarkDecode/size=1e4-8 100 154125 ns/op 40418 B/op
+BenchmarkDecode/size=1e6-8 1 13000839000 ns/op
+goos:
+commit: 7cd9056
+BenchmarkEncode 1 1.5 ns/op
+`, buf.String())
+}
+
+func TestDecoder_Decode_roundTrip(t *testing.T) {
+	run := benchtest.DecodeRun(t, mixedExample)
+	for _, comma := range []rune{',', '\t'} {
+		var table bytes.Buffer
+		require.NoError(t, (&Encoder{Comma: comma, Annotate: true}).Encode(&table, run))
+		decoded, err := Decoder{Comma: comma}.Decode(&table)
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		require.NoError(t, (&benchparse.Encoder{}).Encode(&buf, decoded))
+		require.Equal(t, mixedExample, buf.String())
+	}
+}
+
+func TestDecoder_Decode_errors(t *testing.T) {
+	verifyError := func(in string, expected error) func(t *testing.T) {
+		return func(t *testing.T) {
+			_, err := Decoder{}.Decode(strings.NewReader(in))
+			require.Error(t, err)
+			if rowErr, ok := err.(*RowError); ok {
+				err = rowErr.Err
+			}
+			require.Equal(t, expected, err)
+		}
+	}
+	t.Run("case=empty", verifyError("", ErrNoNameColumn))
+	t.Run("case=noname", verifyError("unit:ns/op\n10\n", ErrNoNameColumn))
+	t.Run("case=unannotated", verifyError("name,commit\n", ErrUnannotatedHeader))
+	t.Run("case=duplicate", verifyError("name,unit:ns/op,unit:ns/op\n", ErrDuplicateHeader))
+	t.Run("case=prefix", verifyError("name,unit:ns/op\nDecode,10\n", benchparse.ErrNoPrefixBenchmark))
+	t.Run("case=upper", verifyError("name,unit:ns/op\nBenchmarkdecode,10\n", benchparse.ErrUpperAfterBench))
+	t.Run("case=spaces", verifyError("name,unit:ns/op\nBenchmarkDecode fast,10\n", ErrNameSpaces))
+	t.Run("case=novalues", verifyError("name,unit:ns/op\nBenchmarkDecode,\n", ErrNoValues))
+	t.Run("case=configspaces", verifyError("name,config:Machine Name,unit:ns/op\n", benchparse.ErrInvalidKeyValueLowercase))
+	t.Run("case=configupper", verifyError("name,config:machineName,unit:ns/op\n", benchparse.ErrInvalidKeyValueSpaces))
+	t.Run("case=configempty", verifyError("name,config:,unit:ns/op\n", benchparse.ErrInvalidKeyValueEmpty))
+	t.Run("case=configcolon", verifyError("name,config:go:version,unit:ns/op\n", ErrInvalidConfigurationKey))
+	t.Run("case=unitspaces", verifyError("name,unit:ns op\n", ErrInvalidUnit))
+	t.Run("case=unitempty", verifyError("name,unit:\n", ErrInvalidUnit))
+	t.Run("case=namekey", verifyError("name,name:a/b,unit:ns/op\n", ErrInvalidNameKey))
+	t.Run("case=namevalue", verifyError("name,name:size,unit:ns/op\nBenchmarkDecode,1 e4,10\n", ErrNameSpaces))
+	t.Run("case=newline", verifyError("name,config:commit,unit:ns/op\nBenchmarkDecode,\"a\nb\",10\n", benchparse.ErrInvalidKeyValueReturn))
+
+	_, err := Decoder{}.Decode(strings.NewReader("name,unit:ns op\n"))
+	require.Equal(t, `row 1: column "unit:ns op": invalid table: unit is empty or has spaces`, err.Error())
+
+	_, err = Decoder{}.Decode(strings.NewReader("name,iterations,unit:ns/op\nBenchmarkDecode,100,10\nBenchmarkDecode,100,fast\n"))
+	rowErr, ok := err.(*RowError)
+	require.True(t, ok)
+	require.Equal(t, 3, rowErr.Row)
+	require.Equal(t, "unit:ns/op", rowErr.Header)
+	_, ok = rowErr.Err.(*strconv.NumError)
+	require.True(t, ok)
+	require.Equal(t, `row 3: column "unit:ns/op": strconv.ParseFloat: parsing "fast": invalid syntax`, rowErr.Error())
+}
@@ -0,0 +1,11 @@
+/*
+Package tabular flattens decoded benchmark runs into tables, such as CSV or TSV files, that spreadsheets and data frame
+libraries can load.  Each BenchmarkResult is a row.  The columns are the benchmark name, the iterations, one column for
+each key of BenchmarkResult.AllKeyValuePairs, and one column for each unit.  Benchmarks named with key=value sub
+benchmarks, like "BenchmarkDecode/size=1e4", get a "size" column.
+
+Decoder reads a table back into a Run, for example to bring historical benchmark data kept in spreadsheets into the
+standard format.  Its header must mark which columns are configuration keys, name keys, and units, the way an Encoder
+with Annotate set writes them.
+*/
+package tabular
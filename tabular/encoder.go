@@ -0,0 +1,79 @@
+package tabular
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/cep21/benchparse"
+	"github.com/cep21/benchparse/analysis"
+)
+
+// Encoder writes a Run as CSV, or as TSV if Comma is a tab.  The first row is a header.  Each following row is a
+// BenchmarkResult.  Columns a result does not have, like a unit it did not report, are empty cells.
+type Encoder struct {
+	// Comma is the field delimiter.  It defaults to ','.  Use '\t' for TSV.
+	Comma rune
+	// Annotate prefixes the header of each key column with ConfigurationPrefix or NamePrefix, and of each unit
+	// column with UnitPrefix.  Configuration keys and name keys with the same key are kept in separate columns.
+	// Annotated headers cannot collide with each other or with the name and iterations columns.  Without Annotate,
+	// headers are the plain key or unit, and a key in both the configuration and the name has the value from the
+	// name, like AllKeyValuePairs.  Plain headers that would collide with another header, like a "name" key or a key
+	// with the same name as a unit, are annotated anyway.
+	Annotate bool
+}
+
+// Encode writes run to w
+func (e *Encoder) Encode(w io.Writer, run *benchparse.Run) error {
+	cols := columns(run, e.Annotate)
+	cw := csv.NewWriter(w)
+	if e.Comma != 0 {
+		cw.Comma = e.Comma
+	}
+	if err := cw.Write(headers(cols, e.Annotate)); err != nil {
+		return err
+	}
+	row := make([]string, len(cols))
+	for _, r := range run.Results {
+		for i, c := range cols {
+			row[i] = c.cell(r)
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// EncodeTable writes a pivot table.  The header is each row key followed by the name of each column, from
+// analysis.Table.ColumnName.  Each following row has the values of the row keys and then the Value of each cell.
+// Empty cells are empty.  Annotate does not apply to pivot tables, which cannot be decoded back into a Run.
+func (e *Encoder) EncodeTable(w io.Writer, t *analysis.Table) error {
+	cw := csv.NewWriter(w)
+	if e.Comma != 0 {
+		cw.Comma = e.Comma
+	}
+	header := append([]string(nil), t.RowKeys...)
+	for j := range t.Columns {
+		header = append(header, t.ColumnName(j))
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for i, values := range t.Rows {
+		row := append([]string(nil), values...)
+		for _, c := range t.Cells[i] {
+			if c.Empty() {
+				row = append(row, "")
+				continue
+			}
+			row = append(row, strconv.FormatFloat(c.Value, 'f', -1, 64))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
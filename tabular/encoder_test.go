@@ -0,0 +1,100 @@
+package tabular
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cep21/benchparse"
+	"github.com/cep21/benchparse/analysis"
+	"github.com/cep21/benchparse/internal/benchtest"
+	"github.com/stretchr/testify/require"
+)
+
+func encode(t *testing.T, e Encoder, run *benchparse.Run) string {
+	var buf bytes.Buffer
+	require.NoError(t, e.Encode(&buf, run))
+	return buf.String()
+}
+
+const mixedExample = `commit: 7cd9055
+goos: linux
+BenchmarkDecode/text=digits/level=speed/size=1e4-8 100 154125 ns/op 64.88 MB/s 40418 B/op 7 allocs/op
+BenchmarkDecode/text=twain/size=1e4-8 100 143125 ns/op 10 misses/op
+commit: 7cd9056
+BenchmarkEncode/fast-8 2000 1.5 ns/op
+`
+
+func TestEncoder_Encode(t *testing.T) {
+	run := benchtest.DecodeRun(t, mixedExample)
+	require.Equal(t, `name,iterations,commit,goos,text,level,size,ns/op,MB/s,B/op,allocs/op,misses/op
+BenchmarkDecode/text=digits/level=speed/size=1e4-8,100,7cd9055,linux,digits,speed,1e4,154125,64.88,40418,7,
+BenchmarkDecode/text=twain/size=1e4-8,100,7cd9055,linux,twain,,1e4,143125,,,,10
+BenchmarkEncode/fast-8,2000,7cd9056,linux,,,,1.5,,,,
+`, encode(t, Encoder{}, run))
+}
+
+func TestEncoder_Encode_tsv(t *testing.T) {
+	run := benchtest.DecodeRun(t, `commit: 7cd9055
+cpu: Intel(R) Core(TM) i7-4980HQ CPU @ 2.80GHz
+BenchmarkBob/size=1e4 100 10 ns/op
+`)
+	require.Equal(t, "name\titerations\tcommit\tcpu\tsize\tns/op\n"+
+		"BenchmarkBob/size=1e4\t100\t7cd9055\tIntel(R) Core(TM) i7-4980HQ CPU @ 2.80GHz\t1e4\t10\n",
+		encode(t, Encoder{Comma: '\t'}, run))
+}
+
+func TestEncoder_Encode_annotate(t *testing.T) {
+	run := benchtest.DecodeRun(t, `size: small
+BenchmarkBob/size=1e4-8 100 10 ns/op
+BenchmarkBob-8 100 20 ns/op
+`)
+	require.Equal(t, `name,iterations,config:size,name:size,unit:ns/op
+BenchmarkBob/size=1e4-8,100,small,1e4,10
+BenchmarkBob-8,100,small,,20
+`, encode(t, Encoder{Annotate: true}, run))
+	require.Equal(t, `name,iterations,size,ns/op
+BenchmarkBob/size=1e4-8,100,1e4,10
+BenchmarkBob-8,100,small,20
+`, encode(t, Encoder{}, run))
+}
+
+func TestEncoder_Encode_collidingHeaders(t *testing.T) {
+	run := benchtest.DecodeRun(t, `allocs/op: 7
+BenchmarkBob/name=bob/iterations=3 100 10 ns/op 5 allocs/op
+`)
+	require.Equal(t, `name,iterations,config:allocs/op,config:name,config:iterations,ns/op,unit:allocs/op
+BenchmarkBob/name=bob/iterations=3,100,7,bob,3,10,5
+`, encode(t, Encoder{}, run))
+}
+
+func TestEncoder_Encode_quoting(t *testing.T) {
+	run := benchtest.DecodeRun(t, `note: a, "quoted" value
+BenchmarkBob 100 10 ns/op
+`)
+	require.Equal(t, `name,iterations,note,ns/op
+BenchmarkBob,100,"a, ""quoted"" value",10
+`, encode(t, Encoder{}, run))
+}
+
+func TestEncoder_Encode_empty(t *testing.T) {
+	require.Equal(t, "name,iterations\n", encode(t, Encoder{}, &benchparse.Run{}))
+}
+
+func TestEncoder_EncodeTable(t *testing.T) {
+	run := benchtest.DecodeRun(t, `BenchmarkDecode/text=digits/size=1e4-8 100 10 ns/op
+BenchmarkDecode/text=digits/size=1e4-8 100 12 ns/op
+BenchmarkDecode/text=digits/size=1e6-8 100 1000 ns/op
+BenchmarkDecode/text=a,b/size=1e6-8 100 20.5 ns/op
+`)
+	var buf bytes.Buffer
+	p := analysis.Pivot{Rows: []string{"text"}, Columns: []string{"size"}, Aggregation: analysis.AggregationMean}
+	require.NoError(t, (&Encoder{}).EncodeTable(&buf, p.Table(run)))
+	require.Equal(t, `text,size=1e4,size=1e6
+digits,11,1000
+"a,b",,20.5
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, (&Encoder{Comma: '\t'}).EncodeTable(&buf, analysis.Pivot{}.Table(run)))
+	require.Equal(t, "ns/op\n16.25\n", buf.String())
+}
@@ -0,0 +1,27 @@
+package tabular_test
+
+import (
+	"os"
+	"strings"
+
+	"github.com/cep21/benchparse"
+	"github.com/cep21/benchparse/tabular"
+)
+
+func ExampleEncoder_Encode() {
+	d := benchparse.Decoder{}
+	run, err := d.Decode(strings.NewReader(`commit: 7cd9055
+BenchmarkDecode/text=digits/size=1e4-8   	     100	    154125 ns/op	  40418 B/op
+BenchmarkDecode/text=twain/size=1e6-8    	       1	13000839000 ns/op
+`))
+	if err != nil {
+		panic(err)
+	}
+	e := tabular.Encoder{}
+	if err := e.Encode(os.Stdout, run); err != nil {
+		panic(err)
+	}
+	// Output: name,iterations,commit,text,size,ns/op,B/op
+	// BenchmarkDecode/text=digits/size=1e4-8,100,7cd9055,digits,1e4,154125,40418
+	// BenchmarkDecode/text=twain/size=1e6-8,1,7cd9055,twain,1e6,13000839000,
+}
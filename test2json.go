@@ -0,0 +1,188 @@
+package benchparse
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// Test2JSONDecoder decodes the output of "go test -json", which is produced by cmd/test2json.  Each line of input is
+// a JSON event.  The output of every "output" event is reassembled into lines per package, since go test often splits
+// a single benchmark result line across two events, and the lines are decoded by Decoder.  Each BenchmarkResult gets
+// the event's Package as the configuration key "pkg".  Decode groups results, and the framing lines go test prints for
+// each package, by package.
+type Test2JSONDecoder struct {
+	// Decoder configures how the reassembled benchmark output is decoded.  Line numbers of any LineError are the line
+	// numbers of the JSON event that completed the line.
+	Decoder Decoder
+}
+
+// test2JSONPackageKey is the configuration key Test2JSONDecoder sets to the package of each event.  It is the same
+// key go test uses to print the package of benchmarks.
+const test2JSONPackageKey = "pkg"
+
+// test2JSONEvent is the part of a cmd/test2json event that Test2JSONDecoder uses
+type test2JSONEvent struct {
+	Action  string
+	Package string
+	Output  string
+}
+
+// test2JSONPackage is the in progress decoding of the output of a single package
+type test2JSONPackage struct {
+	state *decodeState
+	// partialLine is any output after the last newline of the package
+	partialLine string
+}
+
+// test2JSONStream is the state of a single Stream or Decode call
+type test2JSONStream struct {
+	d        Test2JSONDecoder
+	onResult func(pkg string, result BenchmarkResult)
+	packages map[string]*test2JSONPackage
+	// packageOrder is the order packages were first seen
+	packageOrder []string
+}
+
+// Stream allows live processing of benchmarks inside "go test -json" output.  onResult is executed on each
+// BenchmarkResult as soon as the line containing it is complete.  See Decoder.Stream for more complete documentation.
+func (t Test2JSONDecoder) Stream(ctx context.Context, in io.Reader, onResult func(result BenchmarkResult)) error {
+	return t.stream(ctx, in, t.newStream(func(_ string, result BenchmarkResult) {
+		onResult(result)
+	}))
+}
+
+// Decode "go test -json" output into a benchmark run.  Results of each package are contiguous, in the order the
+// package first had output, even if go test interleaved the output of packages.  See Decoder.Decode for more complete
+// documentation.
+func (t Test2JSONDecoder) Decode(in io.Reader) (*Run, error) {
+	results := make(map[string][]BenchmarkResult)
+	stream := t.newStream(func(pkg string, result BenchmarkResult) {
+		results[pkg] = append(results[pkg], result)
+	})
+	if err := t.stream(context.Background(), in, stream); err != nil {
+		return nil, err
+	}
+	ret := &Run{}
+	for _, pkg := range stream.packageOrder {
+		state := stream.packages[pkg].state
+		offset := len(ret.Results)
+		ret.Results = append(ret.Results, results[pkg]...)
+		for _, section := range state.finish() {
+			section.ResultsStart += offset
+			section.ResultsEnd += offset
+			ret.Packages = append(ret.Packages, section)
+		}
+		if state.currentUnits == nil {
+			continue
+		}
+		if ret.Units == nil {
+			ret.Units = &UnitMetadata{}
+		}
+		ret.Units.Merge(state.currentUnits)
+	}
+	return ret, nil
+}
+
+func (t Test2JSONDecoder) newStream(onResult func(pkg string, result BenchmarkResult)) *test2JSONStream {
+	return &test2JSONStream{
+		d:        t,
+		onResult: onResult,
+		packages: make(map[string]*test2JSONPackage),
+	}
+}
+
+func (t Test2JSONDecoder) stream(ctx context.Context, in io.Reader, stream *test2JSONStream) error {
+	b := bufio.NewScanner(in)
+	lineNumber := 0
+	for b.Scan() {
+		lineNumber++
+		if err := stream.decodeEvent(lineNumber, b.Text()); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+	}
+	if b.Err() != nil {
+		return b.Err()
+	}
+	return stream.flush(lineNumber)
+}
+
+// decodeEvent decodes a single line of JSON.  Lines that are not JSON, like build output go test prints around the
+// events, are not decoded, but are reported to OnLineError like any other line the Decoder cannot decode.  Strict does
+// not apply to them, since they are never near misses of a benchmark result.
+func (s *test2JSONStream) decodeEvent(lineNumber int, line string) error {
+	if strings.TrimSpace(line) == "" {
+		return nil
+	}
+	var event test2JSONEvent
+	if err := json.Unmarshal([]byte(line), &event); err != nil {
+		lineErr := &LineError{
+			Line: lineNumber,
+			Text: line,
+			Kind: LineKindTest2JSONEvent,
+			Err:  err,
+		}
+		if s.d.Decoder.OnLineError != nil {
+			s.d.Decoder.OnLineError(lineErr)
+		}
+		return nil
+	}
+	if event.Action != "output" {
+		return nil
+	}
+	pkg := s.pkg(event.Package)
+	buffered := pkg.partialLine + event.Output
+	for {
+		newline := strings.IndexByte(buffered, '\n')
+		if newline == -1 {
+			pkg.partialLine = buffered
+			return nil
+		}
+		line := strings.TrimSuffix(buffered[:newline], "\r")
+		buffered = buffered[newline+1:]
+		if err := pkg.state.decodeLine(lineNumber, line); err != nil {
+			return err
+		}
+	}
+}
+
+// flush decodes output of each package that did not end in a newline
+func (s *test2JSONStream) flush(lineNumber int) error {
+	for _, name := range s.packageOrder {
+		pkg := s.packages[name]
+		if pkg.partialLine == "" {
+			continue
+		}
+		line := pkg.partialLine
+		pkg.partialLine = ""
+		if err := pkg.state.decodeLine(lineNumber, strings.TrimSuffix(line, "\r")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pkg returns the decoding state of a package, creating it if this is the first output of the package
+func (s *test2JSONStream) pkg(name string) *test2JSONPackage {
+	if existing, exists := s.packages[name]; exists {
+		return existing
+	}
+	ret := &test2JSONPackage{
+		state: s.d.Decoder.newDecodeState(func(result BenchmarkResult) {
+			s.onResult(name, result)
+		}),
+	}
+	if name != "" {
+		ret.state.currentKeys.add(test2JSONPackageKey, name)
+	}
+	s.packages[name] = ret
+	s.packageOrder = append(s.packageOrder, name)
+	return ret
+}
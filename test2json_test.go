@@ -0,0 +1,128 @@
+package benchparse
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTest2JSONDecoder_Decode(t *testing.T) {
+	t.Run("example", func(t *testing.T) {
+		d := Test2JSONDecoder{}
+		run, err := d.Decode(strings.NewReader(test2JSONExample))
+		require.NoError(t, err)
+		require.Len(t, run.Results, 3)
+
+		require.Equal(t, "BenchmarkFoo-8 100 3.24 ns/op", run.Results[0].String())
+		require.Equal(t, []string{"goos", "goarch", "pkg"}, run.Results[0].Configuration.Order)
+		require.Equal(t, "github.com/cep21/a", run.Results[0].Configuration.Contents["pkg"])
+
+		require.Equal(t, "BenchmarkBar/size=1-8 100 2.02 ns/op 0 B/op 0 allocs/op", run.Results[1].String())
+		require.Equal(t, []string{"pkg", "goos"}, run.Results[1].Configuration.Order)
+		require.Equal(t, "github.com/cep21/b", run.Results[1].Configuration.Contents["pkg"])
+
+		require.Equal(t, "BenchmarkBar/size=2-8 100 4.02 ns/op", run.Results[2].String())
+		require.Equal(t, "github.com/cep21/b", run.Results[2].Configuration.Contents["pkg"])
+
+		require.Equal(t, []PackageSection{
+			{
+				Package:      "github.com/cep21/a",
+				Status:       PackageStatusOK,
+				Elapsed:      6 * time.Millisecond,
+				ResultsStart: 0,
+				ResultsEnd:   1,
+			},
+			{
+				Package:      "github.com/cep21/b",
+				Status:       PackageStatusIncomplete,
+				ResultsStart: 1,
+				ResultsEnd:   3,
+			},
+		}, run.Packages)
+	})
+	t.Run("units", func(t *testing.T) {
+		d := Test2JSONDecoder{}
+		run, err := d.Decode(strings.NewReader(`{"Action":"output","Package":"a","Output":"Unit ns/op better=lower\n"}
+{"Action":"output","Package":"b","Output":"Unit misses/op better=lower\n"}
+`))
+		require.NoError(t, err)
+		require.Equal(t, []string{"ns/op", "misses/op"}, run.Units.Order)
+	})
+	t.Run("notjson", func(t *testing.T) {
+		var lineErrors []*LineError
+		d := Test2JSONDecoder{
+			Decoder: Decoder{
+				OnLineError: func(err *LineError) {
+					lineErrors = append(lineErrors, err)
+				},
+			},
+		}
+		run, err := d.Decode(strings.NewReader(`# github.com/cep21/a
+{"Action":"output","Package":"a","Output":"BenchmarkFoo 1 1 ns/op\n"}
+`))
+		require.NoError(t, err)
+		require.Len(t, run.Results, 1)
+		require.Len(t, lineErrors, 1)
+		require.Equal(t, 1, lineErrors[0].Line)
+		require.Equal(t, LineKindTest2JSONEvent, lineErrors[0].Kind)
+	})
+	t.Run("strict", func(t *testing.T) {
+		d := Test2JSONDecoder{
+			Decoder: Decoder{Strict: true},
+		}
+		_, err := d.Decode(strings.NewReader(`{"Action":"output","Package":"a","Output":"BenchmarkFoo-8 \t"}
+{"Action":"output","Package":"a","Output":"1 1\n"}
+`))
+		require.Error(t, err)
+		lineErr, ok := err.(*LineError)
+		require.True(t, ok)
+		require.Equal(t, 2, lineErr.Line)
+		require.Equal(t, "BenchmarkFoo-8 \t1 1", lineErr.Text)
+		require.Equal(t, ErrNotEnoughFields, lineErr.Err)
+	})
+	t.Run("strict_notjson", func(t *testing.T) {
+		var lineErrors []*LineError
+		d := Test2JSONDecoder{
+			Decoder: Decoder{
+				Strict: true,
+				OnLineError: func(err *LineError) {
+					lineErrors = append(lineErrors, err)
+				},
+			},
+		}
+		run, err := d.Decode(strings.NewReader(`# github.com/cep21/a
+vet: a.go:1:1: unreachable code
+{"Action":"output","Package":"a","Output":"BenchmarkFoo 1 1 ns/op\n"}
+`))
+		require.NoError(t, err)
+		require.Len(t, run.Results, 1)
+		require.Len(t, lineErrors, 2)
+		require.Equal(t, LineKindTest2JSONEvent, lineErrors[1].Kind)
+	})
+}
+
+func TestTest2JSONDecoder_Stream(t *testing.T) {
+	t.Run("order", func(t *testing.T) {
+		d := Test2JSONDecoder{}
+		var names []string
+		err := d.Stream(context.Background(), strings.NewReader(test2JSONExample), func(result BenchmarkResult) {
+			names = append(names, result.Name)
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"BenchmarkBar/size=1-8", "BenchmarkFoo-8", "BenchmarkBar/size=2-8"}, names)
+	})
+	t.Run("canceled", func(t *testing.T) {
+		ctx, can := context.WithCancel(context.Background())
+		can()
+		d := Test2JSONDecoder{}
+		i := 0
+		err := d.Stream(ctx, strings.NewReader(test2JSONExample), func(_ BenchmarkResult) {
+			i++
+		})
+		require.Error(t, err)
+		require.Equal(t, 0, i)
+	})
+}
@@ -0,0 +1,253 @@
+package benchparse
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Prefix is an SI or binary prefix of a unit, like the "n" of "ns/op" or the "Ki" of "KiB/op"
+type Prefix struct {
+	// Symbol of the prefix as written in a unit.  It is empty for no prefix.
+	Symbol string
+	// Factor is what the prefix multiplies the unit by.  It is 1 for no prefix.
+	Factor float64
+}
+
+// Binary returns true for binary prefixes, like Ki or Mi, that are powers of 1024
+func (p Prefix) Binary() bool {
+	return strings.HasSuffix(p.Symbol, "i")
+}
+
+var (
+	// noPrefix is the Prefix of units without a prefix
+	noPrefix = Prefix{Symbol: "", Factor: 1}
+	// siPrefixes are the SI prefixes this package understands, from smallest to largest
+	siPrefixes = []Prefix{
+		{Symbol: "p", Factor: 1e-12},
+		{Symbol: "n", Factor: 1e-9},
+		{Symbol: "µ", Factor: 1e-6},
+		{Symbol: "m", Factor: 1e-3},
+		noPrefix,
+		{Symbol: "k", Factor: 1e3},
+		{Symbol: "M", Factor: 1e6},
+		{Symbol: "G", Factor: 1e9},
+		{Symbol: "T", Factor: 1e12},
+		{Symbol: "P", Factor: 1e15},
+	}
+	// binaryPrefixes are the binary prefixes this package understands, from smallest to largest
+	binaryPrefixes = []Prefix{
+		noPrefix,
+		{Symbol: "Ki", Factor: 1 << 10},
+		{Symbol: "Mi", Factor: 1 << 20},
+		{Symbol: "Gi", Factor: 1 << 30},
+		{Symbol: "Ti", Factor: 1 << 40},
+		{Symbol: "Pi", Factor: 1 << 50},
+	}
+)
+
+// Dimension is what a unit measures
+type Dimension int
+
+const (
+	// DimensionOther is any unit this package does not know the dimension of, including most custom units from
+	// b.ReportMetric like "allocs" or "misses".  They are treated as counts and never scaled.
+	DimensionOther Dimension = iota
+	// DimensionTime is time, measured in seconds
+	DimensionTime
+	// DimensionBytes is an amount of data, measured in bytes
+	DimensionBytes
+)
+
+func (d Dimension) String() string {
+	switch d {
+	case DimensionOther:
+		return "other"
+	case DimensionTime:
+		return "time"
+	case DimensionBytes:
+		return "bytes"
+	default:
+		return "Dimension(" + strconv.Itoa(int(d)) + ")"
+	}
+}
+
+// baseUnits are the units without a prefix that a Prefix may be parsed from
+var baseUnits = map[string]Dimension{
+	"s":   DimensionTime,
+	"sec": DimensionTime,
+	"B":   DimensionBytes,
+}
+
+// baseUnitSuffixes are the keys of baseUnits, longest first, so a prefix is parsed from the longest base unit a unit
+// ends with no matter the order of the map
+var baseUnitSuffixes = longestFirst(baseUnits)
+
+func longestFirst(units map[string]Dimension) []string {
+	ret := make([]string, 0, len(units))
+	for unit := range units {
+		ret = append(ret, unit)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if len(ret[i]) != len(ret[j]) {
+			return len(ret[i]) > len(ret[j])
+		}
+		return ret[i] < ret[j]
+	})
+	return ret
+}
+
+// ErrIncompatibleUnits is returned when converting between units of different dimensions
+var ErrIncompatibleUnits = errors.New("incompatible units")
+
+// Unit is a parsed benchmark unit.  Units are of the form "numerator/denominator", like "ns/op", "MB/s" or
+// "allocs/op".  Units without a "/", like "x", only have a numerator.  Prefixes are only parsed from units whose
+// Dimension is known, so "ms/op" has the numerator "s" with the prefix "m", but "misses/op" has the numerator "misses".
+type Unit struct {
+	// Numerator is the unit measured without its prefix, like "s" of "ns/op"
+	Numerator string
+	// NumeratorPrefix is the prefix of Numerator, like "n" of "ns/op"
+	NumeratorPrefix Prefix
+	// Denominator is the unit Numerator is measured per without its prefix, like "op" of "ns/op".  It is empty for
+	// units without a "/".
+	Denominator string
+	// DenominatorPrefix is the prefix of Denominator
+	DenominatorPrefix Prefix
+}
+
+// ParseUnit parses a unit, like the Unit of a ValueUnitPair.  Every string is a valid unit.
+func ParseUnit(unit string) Unit {
+	var ret Unit
+	numerator := unit
+	if slash := strings.Index(unit, "/"); slash != -1 {
+		numerator = unit[:slash]
+		ret.DenominatorPrefix, ret.Denominator = parseUnitPart(unit[slash+1:])
+	}
+	ret.NumeratorPrefix, ret.Numerator = parseUnitPart(numerator)
+	return ret
+}
+
+// parseUnitPart splits a unit without a "/" into its prefix and base unit.  Binary prefixes are only for bytes, so
+// "Mis" is a unit of its own rather than mebiseconds.
+func parseUnitPart(part string) (Prefix, string) {
+	if _, exists := baseUnits[part]; exists {
+		return noPrefix, part
+	}
+	for _, base := range baseUnitSuffixes {
+		if !strings.HasSuffix(part, base) {
+			continue
+		}
+		symbol := part[:len(part)-len(base)]
+		// "u" is a common spelling of µ, since µ is hard to type.  The Greek letter mu looks the same as the micro sign.
+		if symbol == "u" || symbol == "μ" {
+			symbol = "µ"
+		}
+		allowed := [][]Prefix{siPrefixes}
+		if baseUnits[base] == DimensionBytes {
+			allowed = append(allowed, binaryPrefixes)
+		}
+		for _, prefixes := range allowed {
+			for _, p := range prefixes {
+				if p.Symbol == symbol {
+					return p, base
+				}
+			}
+		}
+	}
+	return noPrefix, part
+}
+
+func (u Unit) String() string {
+	ret := u.NumeratorPrefix.Symbol + u.Numerator
+	if u.Denominator != "" {
+		ret += "/" + u.DenominatorPrefix.Symbol + u.Denominator
+	}
+	return ret
+}
+
+// Dimension returns what the numerator of the unit measures
+func (u Unit) Dimension() Dimension {
+	return baseUnits[u.Numerator]
+}
+
+// Compatible returns true if values of u can be converted to other, because both have the same numerator and
+// denominator ignoring prefixes.  For example, "ns/op" and "ms/op" are compatible, but "ns/op" and "B/op" are not.
+func (u Unit) Compatible(other Unit) bool {
+	return normalizeBase(u.Numerator) == normalizeBase(other.Numerator) &&
+		normalizeBase(u.Denominator) == normalizeBase(other.Denominator)
+}
+
+// normalizeBase returns the same string for every spelling of a base unit
+func normalizeBase(base string) string {
+	if base == "sec" {
+		return "s"
+	}
+	return base
+}
+
+// Convert converts value, in the unit u, into the unit to.  Returns ErrIncompatibleUnits if the units are not
+// Compatible.
+func (u Unit) Convert(value float64, to Unit) (float64, error) {
+	if !u.Compatible(to) {
+		return 0, ErrIncompatibleUnits
+	}
+	return value * u.NumeratorPrefix.Factor / to.NumeratorPrefix.Factor * to.DenominatorPrefix.Factor / u.DenominatorPrefix.Factor, nil
+}
+
+// Scale returns value converted to the unit with the same denominator whose numerator prefix makes value easiest for a
+// human to read, which is the largest prefix that keeps the absolute value at least 1.  Units of binary prefixes are
+// scaled with binary prefixes.  Units of DimensionOther, and zero, infinite or NaN values, are returned unchanged.
+// For example, 1234567 ns/op scales to 1.234567 ms/op.
+func (u Unit) Scale(value float64) (float64, Unit) {
+	if u.Dimension() == DimensionOther || value == 0 || math.IsInf(value, 0) || math.IsNaN(value) {
+		return value, u
+	}
+	prefixes := siPrefixes
+	if u.NumeratorPrefix.Binary() {
+		prefixes = binaryPrefixes
+	}
+	if u.Dimension() == DimensionBytes {
+		// There is no such thing as a fraction of a byte, so never scale below bytes
+		prefixes = prefixesAtLeast(prefixes, 1)
+	}
+	if u.Dimension() == DimensionTime {
+		// Larger units of time are minutes and hours, not kiloseconds
+		prefixes = prefixesAtMost(prefixes, 1)
+	}
+	absBase := math.Abs(value * u.NumeratorPrefix.Factor)
+	best := prefixes[0]
+	for _, p := range prefixes {
+		if absBase/p.Factor >= 1 {
+			best = p
+		}
+	}
+	to := u
+	to.NumeratorPrefix = best
+	// Convert can't fail, since only the prefix differs
+	ret, _ := u.Convert(value, to)
+	return ret, to
+}
+
+// prefixesAtLeast returns the prefixes with a Factor of at least factor
+func prefixesAtLeast(prefixes []Prefix, factor float64) []Prefix {
+	ret := make([]Prefix, 0, len(prefixes))
+	for _, p := range prefixes {
+		if p.Factor >= factor {
+			ret = append(ret, p)
+		}
+	}
+	return ret
+}
+
+// prefixesAtMost returns the prefixes with a Factor of at most factor
+func prefixesAtMost(prefixes []Prefix, factor float64) []Prefix {
+	ret := make([]Prefix, 0, len(prefixes))
+	for _, p := range prefixes {
+		if p.Factor <= factor {
+			ret = append(ret, p)
+		}
+	}
+	return ret
+}
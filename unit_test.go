@@ -0,0 +1,144 @@
+package benchparse
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLongestFirst(t *testing.T) {
+	require.Equal(t, []string{"sec", "B", "s"}, baseUnitSuffixes)
+	require.Equal(t, []string{"ab", "xy", "a", "b"}, longestFirst(map[string]Dimension{"b": 0, "xy": 0, "a": 0, "ab": 0}))
+}
+
+func TestParseUnit(t *testing.T) {
+	verifyParses := func(unit string, expected Unit, dimension Dimension) func(t *testing.T) {
+		return func(t *testing.T) {
+			u := ParseUnit(unit)
+			require.Equal(t, expected, u)
+			require.Equal(t, dimension, u.Dimension())
+		}
+	}
+	t.Run("case=ns/op", verifyParses("ns/op", Unit{
+		Numerator:         "s",
+		NumeratorPrefix:   Prefix{Symbol: "n", Factor: 1e-9},
+		Denominator:       "op",
+		DenominatorPrefix: noPrefix,
+	}, DimensionTime))
+	t.Run("case=MB/s", verifyParses("MB/s", Unit{
+		Numerator:         "B",
+		NumeratorPrefix:   Prefix{Symbol: "M", Factor: 1e6},
+		Denominator:       "s",
+		DenominatorPrefix: noPrefix,
+	}, DimensionBytes))
+	t.Run("case=B/op", verifyParses("B/op", Unit{
+		Numerator:         "B",
+		NumeratorPrefix:   noPrefix,
+		Denominator:       "op",
+		DenominatorPrefix: noPrefix,
+	}, DimensionBytes))
+	t.Run("case=allocs/op", verifyParses("allocs/op", Unit{
+		Numerator:         "allocs",
+		NumeratorPrefix:   noPrefix,
+		Denominator:       "op",
+		DenominatorPrefix: noPrefix,
+	}, DimensionOther))
+	t.Run("case=misses/op", verifyParses("misses/op", Unit{
+		Numerator:         "misses",
+		NumeratorPrefix:   noPrefix,
+		Denominator:       "op",
+		DenominatorPrefix: noPrefix,
+	}, DimensionOther))
+	t.Run("case=KiB/op", verifyParses("KiB/op", Unit{
+		Numerator:         "B",
+		NumeratorPrefix:   Prefix{Symbol: "Ki", Factor: 1024},
+		Denominator:       "op",
+		DenominatorPrefix: noPrefix,
+	}, DimensionBytes))
+	t.Run("case=Mis/op", verifyParses("Mis/op", Unit{
+		Numerator:         "Mis",
+		NumeratorPrefix:   noPrefix,
+		Denominator:       "op",
+		DenominatorPrefix: noPrefix,
+	}, DimensionOther))
+	t.Run("case=us/op", verifyParses("us/op", Unit{
+		Numerator:         "s",
+		NumeratorPrefix:   Prefix{Symbol: "µ", Factor: 1e-6},
+		Denominator:       "op",
+		DenominatorPrefix: noPrefix,
+	}, DimensionTime))
+	t.Run("case=msec/op", verifyParses("msec/op", Unit{
+		Numerator:         "sec",
+		NumeratorPrefix:   Prefix{Symbol: "m", Factor: 1e-3},
+		Denominator:       "op",
+		DenominatorPrefix: noPrefix,
+	}, DimensionTime))
+	t.Run("case=sec/op", verifyParses("sec/op", Unit{
+		Numerator:         "sec",
+		NumeratorPrefix:   noPrefix,
+		Denominator:       "op",
+		DenominatorPrefix: noPrefix,
+	}, DimensionTime))
+	t.Run("case=B/ms", verifyParses("B/ms", Unit{
+		Numerator:         "B",
+		NumeratorPrefix:   noPrefix,
+		Denominator:       "s",
+		DenominatorPrefix: Prefix{Symbol: "m", Factor: 1e-3},
+	}, DimensionBytes))
+	t.Run("case=noslash", verifyParses("x", Unit{
+		Numerator:       "x",
+		NumeratorPrefix: noPrefix,
+	}, DimensionOther))
+}
+
+func TestUnit_String(t *testing.T) {
+	for _, u := range []string{"ns/op", "MB/s", "B/op", "allocs/op", "KiB/op", "x", "B/ms"} {
+		require.Equal(t, u, ParseUnit(u).String())
+	}
+	require.Equal(t, "µs/op", ParseUnit("us/op").String())
+}
+
+func TestUnit_Convert(t *testing.T) {
+	verifyConverts := func(value float64, from string, to string, expected float64) func(t *testing.T) {
+		return func(t *testing.T) {
+			ret, err := ParseUnit(from).Convert(value, ParseUnit(to))
+			require.NoError(t, err)
+			require.InDelta(t, expected, ret, math.Abs(expected)*1e-12)
+		}
+	}
+	t.Run("case=ns-ms", verifyConverts(1234567, "ns/op", "ms/op", 1.234567))
+	t.Run("case=ms-ns", verifyConverts(1.5, "ms/op", "ns/op", 1500000))
+	t.Run("case=sec-ns", verifyConverts(1, "sec/op", "ns/op", 1e9))
+	t.Run("case=MB-KiB", verifyConverts(1.024, "MB/s", "KiB/s", 1000))
+	t.Run("case=denominator", verifyConverts(1, "B/ms", "B/s", 1000))
+	t.Run("case=same", verifyConverts(3, "allocs/op", "allocs/op", 3))
+
+	_, err := ParseUnit("ns/op").Convert(1, ParseUnit("B/op"))
+	require.Equal(t, ErrIncompatibleUnits, err)
+	_, err = ParseUnit("MB/s").Convert(1, ParseUnit("MB/op"))
+	require.Equal(t, ErrIncompatibleUnits, err)
+}
+
+func TestUnit_Scale(t *testing.T) {
+	verifyScales := func(value float64, unit string, expectedValue float64, expectedUnit string) func(t *testing.T) {
+		return func(t *testing.T) {
+			ret, u := ParseUnit(unit).Scale(value)
+			require.InDelta(t, expectedValue, ret, math.Abs(expectedValue)*1e-12)
+			require.Equal(t, expectedUnit, u.String())
+		}
+	}
+	t.Run("case=ns", verifyScales(154125, "ns/op", 154.125, "µs/op"))
+	t.Run("case=ms", verifyScales(1234567, "ns/op", 1.234567, "ms/op"))
+	t.Run("case=s", verifyScales(13000839000, "ns/op", 13.000839, "s/op"))
+	t.Run("case=ps", verifyScales(0.5, "ns/op", 500, "ps/op"))
+	t.Run("case=smallns", verifyScales(3.2, "ns/op", 3.2, "ns/op"))
+	t.Run("case=bytes", verifyScales(40418, "B/op", 40.418, "kB/op"))
+	t.Run("case=fractionbytes", verifyScales(0.5, "B/op", 0.5, "B/op"))
+	t.Run("case=mbs", verifyScales(64.88, "MB/s", 64.88, "MB/s"))
+	t.Run("case=gbs", verifyScales(6488, "MB/s", 6.488, "GB/s"))
+	t.Run("case=binary", verifyScales(2048, "KiB/op", 2, "MiB/op"))
+	t.Run("case=negative", verifyScales(-2000, "ns/op", -2, "µs/op"))
+	t.Run("case=other", verifyScales(4000, "allocs/op", 4000, "allocs/op"))
+	t.Run("case=zero", verifyScales(0, "ns/op", 0, "ns/op"))
+}
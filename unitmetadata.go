@@ -0,0 +1,113 @@
+package benchparse
+
+// UnitMetadata is the key=value metadata attached to units by "Unit" lines, such as "Unit ns/op assume=exact
+// better=lower".  Like configuration, unit metadata describes all benchmark results that follow it.  Metadata
+// for the same unit accumulates between lines, with later values overwriting earlier ones for the same key.
+type UnitMetadata struct {
+	// Contents maps a unit to its metadata
+	Contents map[string]*OrderedStringStringMap
+	// Order is the order units were first described.  It is intended that len(Order) == len(Contents) and the keys
+	// of Contents are all inside Order.
+	Order []string
+}
+
+// Lookup returns the value of a metadata key for unit.  Returns false if the unit or key does not exist.  It is safe
+// to call Lookup on a nil UnitMetadata.
+func (u *UnitMetadata) Lookup(unit string, key string) (string, bool) {
+	if u == nil {
+		return "", false
+	}
+	m, exists := u.Contents[unit]
+	if !exists {
+		return "", false
+	}
+	v, exists := m.Contents[key]
+	return v, exists
+}
+
+// Merge adds the metadata of every unit of other to u, in the order of other.  Like a later Unit line, metadata of
+// other replaces the value of the same key of the same unit.  Merging a nil UnitMetadata does nothing.
+func (u *UnitMetadata) Merge(other *UnitMetadata) {
+	if other == nil {
+		return
+	}
+	for _, unit := range other.Order {
+		u.add(unit, other.Contents[unit])
+	}
+}
+
+// add merges metadata into the existing metadata for unit.  metadata may be nil.
+func (u *UnitMetadata) add(unit string, metadata *OrderedStringStringMap) {
+	if u.Contents == nil {
+		u.Contents = make(map[string]*OrderedStringStringMap)
+	}
+	existing, exists := u.Contents[unit]
+	if !exists {
+		existing = &OrderedStringStringMap{}
+		u.Contents[unit] = existing
+		u.Order = append(u.Order, unit)
+	}
+	if metadata == nil {
+		return
+	}
+	for _, k := range metadata.Order {
+		existing.add(k, metadata.Contents[k])
+	}
+}
+
+// Clone makes a deep copy of this object.  The Clone of nil is nil.
+func (u *UnitMetadata) Clone() *UnitMetadata {
+	if u == nil {
+		return nil
+	}
+	ret := &UnitMetadata{}
+	ret.Merge(u)
+	return ret
+}
+
+// equal returns true if both have the same metadata for the same units in the same order.  A nil UnitMetadata is
+// equal to an empty one.
+func (u *UnitMetadata) equal(other *UnitMetadata) bool {
+	var order, otherOrder []string
+	if u != nil {
+		order = u.Order
+	}
+	if other != nil {
+		otherOrder = other.Order
+	}
+	if len(order) != len(otherOrder) {
+		return false
+	}
+	for i, unit := range order {
+		if unit != otherOrder[i] || !u.Contents[unit].equal(other.Contents[unit]) {
+			return false
+		}
+	}
+	return true
+}
+
+// valuesToTransition returns the Unit lines, as unit to metadata, required to transition from the current unit
+// metadata to newState.  Unit metadata cannot be removed, so only additions and changes are returned.
+func (u *UnitMetadata) valuesToTransition(newState *UnitMetadata) *UnitMetadata {
+	ret := &UnitMetadata{}
+	if u == newState || newState == nil {
+		return ret
+	}
+	for _, unit := range newState.Order {
+		newMetadata := newState.Contents[unit]
+		var oldMetadata *OrderedStringStringMap
+		if u != nil {
+			oldMetadata = u.Contents[unit]
+		}
+		for _, k := range newMetadata.Order {
+			v := newMetadata.Contents[k]
+			if oldMetadata == nil || !oldMetadata.exists(k, v) {
+				ret.add(unit, &OrderedStringStringMap{
+					Contents: map[string]string{k: v},
+					Order:    []string{k},
+				})
+			}
+		}
+	}
+	return ret
+}